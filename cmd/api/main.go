@@ -30,13 +30,15 @@ func main() {
 	env := config.Get("APP_ENV")
 	if env == "production" {
 		utils.InfoLogger.Println("Running in production mode. Scheduling cron jobs accordingly.")
-		c.AddFunc("0 5 * * *", workers.CheckLongMenstrualCycles) // setiap jam 05:00 pagi
-		c.AddFunc("0 5 * * *", workers.CheckLateMenstrualCycles) // setiap jam 05:00 pagi
+		c.AddFunc("0 5 * * *", workers.CheckLongMenstrualCycles)       // setiap jam 05:00 pagi
+		c.AddFunc("0 5 * * *", workers.CheckLateMenstrualCycles)       // setiap jam 05:00 pagi
+		c.AddFunc("0 3 * * *", workers.PurgeScheduledAccountDeletions) // setiap jam 03:00 pagi
 		utils.InfoLogger.Println("Scheduled cron jobs for production at 05:00 AM daily.")
 	} else {
 		utils.InfoLogger.Println("Running in development mode. Scheduling cron jobs for testing.")
-		c.AddFunc("@every 1m", workers.CheckLongMenstrualCycles) // setiap 1 menit (testing)
-		c.AddFunc("@every 1m", workers.CheckLateMenstrualCycles) // setiap 1 menit (testing)
+		c.AddFunc("@every 1m", workers.CheckLongMenstrualCycles)       // setiap 1 menit (testing)
+		c.AddFunc("@every 1m", workers.CheckLateMenstrualCycles)       // setiap 1 menit (testing)
+		c.AddFunc("@every 1m", workers.PurgeScheduledAccountDeletions) // setiap 1 menit (testing)
 		utils.InfoLogger.Println("Scheduled cron jobs for development every 1 minute.")
 	}
 	c.Start()
@@ -46,6 +48,10 @@ func main() {
 	utils.InitFCM()
 	database.ConnectDB()
 
+	if err := utils.InitializeJWTKeys(); err != nil {
+		log.Fatalf("Failed to load JWT signing keys: %v", err)
+	}
+
 	utils.SetupValidator()
 	utils.InitializeRegistrationFilter()
 	utils.InitializeFrequentLoginFilter()
@@ -22,6 +22,7 @@ func dropAllTables(db *gorm.DB) error {
 		"notifications",
 		"settings",
 		"recommendations",
+		"flow_logs",
 		"symptom_log_details",
 		"symptom_logs",
 		"symptom_options",
@@ -33,6 +34,10 @@ func dropAllTables(db *gorm.DB) error {
 		"regencies",
 		"provinces",
 		"classifications",
+		"consents",
+		"two_factor_recovery_codes",
+		"sessions",
+		"login_lockouts",
 		"invalid_tokens",
 		"user_roles",
 		"user_permissions",
@@ -108,6 +113,20 @@ func main() {
 		migrations.CreateMaintenanceTables(),
 		migrations.AddEmailVerification(),
 		migrations.AddLastOtpSentAt(),
+		migrations.CreateSessionsTable(),
+		migrations.RevokeTokensByJti(),
+		migrations.AddPasswordResetToUsers(),
+		migrations.HashVerificationOtp(),
+		migrations.AddDeviceNameToSessions(),
+		migrations.AddTwoFactorAuthentication(),
+		migrations.AddAccountDeletionToUsers(),
+		migrations.AddEmailChangeToUsers(),
+		migrations.CreateLoginLockoutsTable(),
+		migrations.CreateConsentsTable(),
+		migrations.SeedAdminPermissions(),
+		migrations.AddRegionScopeToRoles(),
+		migrations.CreateFlowLogsTable(),
+		migrations.AddSeverityToSymptomLogDetails(),
 		// And more...
 	})
 
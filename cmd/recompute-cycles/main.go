@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"ipincamp/srikandi-sehat/config"
+	"ipincamp/srikandi-sehat/database"
+	"ipincamp/srikandi-sehat/src/models"
+	"ipincamp/srikandi-sehat/src/utils"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// Menghitung ulang field turunan siklus (period_length, is_period_normal, cycle_length, is_cycle_normal),
+// symptom_logs.menstrual_cycle_id, serta flow_logs.menstrual_cycle_id/is_intermenstrual
+// dari data siklus yang tersimpan.
+//
+// Contoh:
+//
+//	go run ./cmd/recompute-cycles                     # dry-run untuk semua user
+//	go run ./cmd/recompute-cycles -user=<uuid|email>  # dry-run untuk satu user
+//	go run ./cmd/recompute-cycles -apply -batch=200   # simpan perubahan, satu transaksi per batch
+func main() {
+	userFlag := flag.String("user", "", "UUID atau email user (kosong = semua user)")
+	batchSize := flag.Int("batch", 100, "Jumlah user per batch")
+	apply := flag.Bool("apply", false, "Simpan perubahan (default: dry-run, hanya laporkan perbedaan)")
+	flag.Parse()
+
+	if *batchSize <= 0 {
+		log.Fatal("[CYCLE] [RECOMPUTE] Batch size must be greater than zero")
+	}
+
+	config.LoadConfig()
+	config.SetTimeZone()
+	database.ConnectDB()
+
+	mode := "DRY-RUN"
+	if *apply {
+		mode = "APPLY"
+	}
+	log.Printf("[CYCLE] [RECOMPUTE] Starting in %s mode...", mode)
+
+	query := database.DB.Model(&models.User{}).Select("id", "uuid")
+	if *userFlag != "" {
+		query = query.Where("uuid = ? OR email = ?", *userFlag, *userFlag)
+	}
+
+	var users []models.User
+	var totalUsers, affectedUsers, totalChanges int
+	result := query.FindInBatches(&users, *batchSize, func(batchTx *gorm.DB, batch int) error {
+		// Mode apply: seluruh user dalam satu batch disimpan dalam satu transaksi.
+		return database.DB.Transaction(func(tx *gorm.DB) error {
+			for _, user := range users {
+				totalUsers++
+
+				changes, err := utils.PlanCycleRecompute(tx, user.ID)
+				if err != nil {
+					return err
+				}
+				if len(changes) == 0 {
+					continue
+				}
+
+				affectedUsers++
+				totalChanges += len(changes)
+				for _, change := range changes {
+					log.Printf("[CYCLE] [RECOMPUTE] user %s: %s", user.UUID, change)
+				}
+
+				if *apply {
+					if err := utils.ApplyCycleRecompute(tx, changes); err != nil {
+						return err
+					}
+				}
+			}
+
+			log.Printf("[CYCLE] [RECOMPUTE] Batch %d processed (%d users).", batch, len(users))
+			return nil
+		})
+	})
+	if result.Error != nil {
+		log.Fatalf("[CYCLE] [RECOMPUTE] Failed, current batch rolled back: %v", result.Error)
+	}
+
+	if *userFlag != "" && totalUsers == 0 {
+		log.Fatalf("[CYCLE] [RECOMPUTE] User not found: %s", *userFlag)
+	}
+
+	outcome := "would be updated"
+	if *apply {
+		outcome = "updated"
+	}
+	log.Printf("[CYCLE] [RECOMPUTE] %s finished: %d users checked, %d users with differences, %d fields %s.",
+		mode, totalUsers, affectedUsers, totalChanges, outcome)
+}
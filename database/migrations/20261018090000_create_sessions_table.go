@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func CreateSessionsTable() *gormigrate.Migration {
+	type Session struct {
+		ID               uint       `gorm:"primarykey"`
+		UUID             string     `gorm:"type:char(36);uniqueIndex;not null"`
+		UserID           uint       `gorm:"not null;index;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+		RefreshTokenHash string     `gorm:"type:char(64);not null"`
+		UserAgent        string     `gorm:"type:varchar(255)"`
+		IPAddress        string     `gorm:"type:varchar(45)"`
+		LastUsedAt       time.Time  `gorm:"type:datetime(3);not null"`
+		ExpiresAt        time.Time  `gorm:"type:datetime(3);not null;index"`
+		RevokedAt        *time.Time `gorm:"type:datetime(3);null;index"`
+		RevokedReason    *string    `gorm:"type:varchar(50);null"`
+		CreatedAt        time.Time  `gorm:"autoCreateTime"`
+		UpdatedAt        time.Time  `gorm:"autoUpdateTime"`
+	}
+
+	return &gormigrate.Migration{
+		ID: "20261018090000",
+
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&Session{})
+		},
+
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&Session{})
+		},
+	}
+}
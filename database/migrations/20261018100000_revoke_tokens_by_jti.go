@@ -0,0 +1,55 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func RevokeTokensByJti() *gormigrate.Migration {
+	type InvalidToken struct {
+		ID        uint      `gorm:"primarykey"`
+		JTI       string    `gorm:"column:jti;type:char(36);uniqueIndex;not null"`
+		ExpiresAt time.Time `gorm:"not null;index"`
+	}
+
+	type Session struct {
+		AccessTokenJTI       string     `gorm:"column:access_token_jti;type:char(36)"`
+		AccessTokenExpiresAt *time.Time `gorm:"column:access_token_expires_at;type:datetime(3);null"`
+	}
+
+	return &gormigrate.Migration{
+		ID: "20261018100000",
+
+		Migrate: func(tx *gorm.DB) error {
+			// Token lama disimpan utuh (bukan jti) dan tidak lagi berguna, jadi tabelnya dibuat ulang.
+			if err := tx.Migrator().DropTable("invalid_tokens"); err != nil {
+				return err
+			}
+			if err := tx.AutoMigrate(&InvalidToken{}); err != nil {
+				return err
+			}
+			return tx.AutoMigrate(&Session{})
+		},
+
+		Rollback: func(tx *gorm.DB) error {
+			type LegacyInvalidToken struct {
+				ID        uint      `gorm:"primarykey"`
+				Token     string    `gorm:"type:text;uniqueIndex;not null"`
+				ExpiresAt time.Time `gorm:"not null"`
+			}
+
+			if err := tx.Migrator().DropColumn(&Session{}, "access_token_jti"); err != nil {
+				return err
+			}
+			if err := tx.Migrator().DropColumn(&Session{}, "access_token_expires_at"); err != nil {
+				return err
+			}
+			if err := tx.Migrator().DropTable(&InvalidToken{}); err != nil {
+				return err
+			}
+			return tx.Table("invalid_tokens").AutoMigrate(&LegacyInvalidToken{})
+		},
+	}
+}
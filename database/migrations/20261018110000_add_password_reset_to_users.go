@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func AddPasswordResetToUsers() *gormigrate.Migration {
+	type User struct {
+		PasswordResetToken     *string    `gorm:"column:password_reset_token;type:varchar(255);null"`
+		PasswordResetExpiresAt *time.Time `gorm:"column:password_reset_expires_at;type:datetime(3);null"`
+		PasswordResetAttempts  uint       `gorm:"column:password_reset_attempts;not null;default:0"`
+	}
+
+	return &gormigrate.Migration{
+		ID: "20261018110000",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&User{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropColumn(&User{}, "password_reset_token"); err != nil {
+				return err
+			}
+			if err := tx.Migrator().DropColumn(&User{}, "password_reset_expires_at"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&User{}, "password_reset_attempts")
+		},
+	}
+}
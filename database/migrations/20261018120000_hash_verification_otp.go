@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func HashVerificationOtp() *gormigrate.Migration {
+	type User struct {
+		VerificationAttempts uint `gorm:"column:verification_attempts;not null;default:0"`
+	}
+
+	return &gormigrate.Migration{
+		ID: "20261018120000",
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&User{}); err != nil {
+				return err
+			}
+			// OTP lama tersimpan plaintext; batalkan agar user meminta kode baru yang sudah di-hash.
+			return tx.Table("users").
+				Where("verification_token IS NOT NULL").
+				Updates(map[string]interface{}{
+					"verification_token":      nil,
+					"verification_expires_at": nil,
+				}).Error
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&User{}, "verification_attempts")
+		},
+	}
+}
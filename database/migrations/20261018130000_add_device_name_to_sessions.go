@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func AddDeviceNameToSessions() *gormigrate.Migration {
+	type Session struct {
+		DeviceName string `gorm:"type:varchar(100)"`
+	}
+
+	return &gormigrate.Migration{
+		ID: "20261018130000",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&Session{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&Session{}, "device_name")
+		},
+	}
+}
@@ -0,0 +1,48 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func AddTwoFactorAuthentication() *gormigrate.Migration {
+	type User struct {
+		TwoFactorSecret    *string    `gorm:"column:two_factor_secret;type:varchar(255);null"`
+		TwoFactorEnabledAt *time.Time `gorm:"column:two_factor_enabled_at;type:datetime(3);null"`
+		TwoFactorLastStep  int64      `gorm:"column:two_factor_last_step;not null;default:0"`
+	}
+
+	type TwoFactorRecoveryCode struct {
+		ID        uint       `gorm:"primarykey"`
+		UserID    uint       `gorm:"not null;index;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+		CodeHash  string     `gorm:"type:char(64);not null"`
+		UsedAt    *time.Time `gorm:"type:datetime(3);null"`
+		CreatedAt time.Time  `gorm:"autoCreateTime"`
+	}
+
+	return &gormigrate.Migration{
+		ID: "20261018140000",
+
+		Migrate: func(tx *gorm.DB) error {
+			if err := tx.AutoMigrate(&User{}); err != nil {
+				return err
+			}
+			return tx.AutoMigrate(&TwoFactorRecoveryCode{})
+		},
+
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropTable(&TwoFactorRecoveryCode{}); err != nil {
+				return err
+			}
+			if err := tx.Migrator().DropColumn(&User{}, "two_factor_secret"); err != nil {
+				return err
+			}
+			if err := tx.Migrator().DropColumn(&User{}, "two_factor_enabled_at"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&User{}, "two_factor_last_step")
+		},
+	}
+}
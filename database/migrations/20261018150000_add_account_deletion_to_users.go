@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func AddAccountDeletionToUsers() *gormigrate.Migration {
+	type User struct {
+		DeletionRequestedAt *time.Time `gorm:"column:deletion_requested_at;type:datetime(3);null"`
+		DeletionScheduledAt *time.Time `gorm:"column:deletion_scheduled_at;type:datetime(3);null;index"`
+	}
+
+	return &gormigrate.Migration{
+		ID: "20261018150000",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&User{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Migrator().DropColumn(&User{}, "deletion_requested_at"); err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&User{}, "deletion_scheduled_at")
+		},
+	}
+}
@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func AddEmailChangeToUsers() *gormigrate.Migration {
+	type User struct {
+		PendingEmail         *string    `gorm:"column:pending_email;type:varchar(255);null"`
+		EmailChangeToken     *string    `gorm:"column:email_change_token;type:varchar(255);null"`
+		EmailChangeExpiresAt *time.Time `gorm:"column:email_change_expires_at;type:datetime(3);null"`
+		EmailChangeAttempts  uint       `gorm:"column:email_change_attempts;not null;default:0"`
+	}
+
+	return &gormigrate.Migration{
+		ID: "20261018160000",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&User{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			for _, column := range []string{"pending_email", "email_change_token", "email_change_expires_at", "email_change_attempts"} {
+				if err := tx.Migrator().DropColumn(&User{}, column); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func CreateLoginLockoutsTable() *gormigrate.Migration {
+	type LoginLockout struct {
+		ID             uint       `gorm:"primarykey"`
+		Scope          string     `gorm:"type:varchar(10);not null;uniqueIndex:idx_login_lockouts_scope_identifier"`
+		Identifier     string     `gorm:"type:varchar(64);not null;uniqueIndex:idx_login_lockouts_scope_identifier"`
+		FailedAttempts uint       `gorm:"not null;default:0"`
+		LockoutCount   uint       `gorm:"not null;default:0"`
+		LockedUntil    *time.Time `gorm:"type:datetime(3);null"`
+		LastFailedAt   time.Time  `gorm:"type:datetime(3);not null;index"`
+		CreatedAt      time.Time  `gorm:"autoCreateTime"`
+		UpdatedAt      time.Time  `gorm:"autoUpdateTime"`
+	}
+
+	return &gormigrate.Migration{
+		ID: "20261018170000",
+
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&LoginLockout{})
+		},
+
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&LoginLockout{})
+		},
+	}
+}
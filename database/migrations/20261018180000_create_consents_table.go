@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func CreateConsentsTable() *gormigrate.Migration {
+	type Consent struct {
+		ID             uint       `gorm:"primarykey"`
+		UUID           string     `gorm:"type:char(36);uniqueIndex;not null"`
+		UserID         uint       `gorm:"not null;index;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+		Version        string     `gorm:"type:varchar(20);not null;index"`
+		Type           string     `gorm:"type:enum('self','guardian');not null"`
+		GuardianName   *string    `gorm:"type:varchar(100);null"`
+		GuardianEmail  *string    `gorm:"type:varchar(255);null"`
+		TokenHash      *string    `gorm:"type:char(64);null;uniqueIndex"`
+		TokenExpiresAt *time.Time `gorm:"type:datetime(3);null"`
+		GrantedAt      *time.Time `gorm:"type:datetime(3);null"`
+		GrantedIP      *string    `gorm:"column:granted_ip;type:varchar(45);null"`
+		RevokedAt      *time.Time `gorm:"type:datetime(3);null"`
+		CreatedAt      time.Time  `gorm:"autoCreateTime"`
+		UpdatedAt      time.Time  `gorm:"autoUpdateTime"`
+	}
+
+	return &gormigrate.Migration{
+		ID: "20261018180000",
+
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&Consent{})
+		},
+
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&Consent{})
+		},
+	}
+}
@@ -0,0 +1,78 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SeedAdminPermissions menambahkan permission untuk rute /api/admin dan memberikannya ke role admin
+// yang sudah ada, agar admin tetap punya akses setelah rute beralih dari AdminMiddleware ke PermissionMiddleware.
+func SeedAdminPermissions() *gormigrate.Migration {
+	type Permission struct {
+		ID        uint      `gorm:"primarykey"`
+		Name      string    `gorm:"type:varchar(100);uniqueIndex;not null"`
+		CreatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+		UpdatedAt time.Time `gorm:"default:CURRENT_TIMESTAMP"`
+	}
+
+	type Role struct {
+		ID   uint
+		Name string
+	}
+
+	// Disalin dari constants.AllPermissions saat migrasi ini dibuat.
+	permissionNames := []string{
+		"users.read",
+		"users.manage",
+		"roles.manage",
+		"reports.export",
+		"maintenance.manage",
+	}
+
+	return &gormigrate.Migration{
+		ID: "20261018190000",
+
+		Migrate: func(tx *gorm.DB) error {
+			permissions := make([]Permission, 0, len(permissionNames))
+			for _, name := range permissionNames {
+				permissions = append(permissions, Permission{Name: name})
+			}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&permissions).Error; err != nil {
+				return err
+			}
+
+			// Pada database baru role admin belum ada; seeder yang akan memberikan permission-nya.
+			var admin Role
+			if err := tx.Where("name = ?", "admin").Limit(1).Find(&admin).Error; err != nil {
+				return err
+			}
+			if admin.ID == 0 {
+				return nil
+			}
+
+			return tx.Exec(
+				"INSERT IGNORE INTO role_permissions (role_id, permission_id) SELECT ?, id FROM permissions WHERE name IN ?",
+				admin.ID, permissionNames,
+			).Error
+		},
+
+		Rollback: func(tx *gorm.DB) error {
+			if err := tx.Exec(
+				"DELETE FROM role_permissions WHERE permission_id IN (SELECT id FROM permissions WHERE name IN ?)",
+				permissionNames,
+			).Error; err != nil {
+				return err
+			}
+			if err := tx.Exec(
+				"DELETE FROM user_permissions WHERE permission_id IN (SELECT id FROM permissions WHERE name IN ?)",
+				permissionNames,
+			).Error; err != nil {
+				return err
+			}
+			return tx.Where("name IN ?", permissionNames).Delete(&Permission{}).Error
+		},
+	}
+}
@@ -0,0 +1,22 @@
+package migrations
+
+import (
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func AddRegionScopeToRoles() *gormigrate.Migration {
+	type Role struct {
+		RegionCode *string `gorm:"column:region_code;type:varchar(10);null;index"`
+	}
+
+	return &gormigrate.Migration{
+		ID: "20261018200000",
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&Role{})
+		},
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&Role{}, "region_code")
+		},
+	}
+}
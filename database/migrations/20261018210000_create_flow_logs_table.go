@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"time"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func CreateFlowLogsTable() *gormigrate.Migration {
+	type FlowLog struct {
+		ID               uint      `gorm:"primarykey"`
+		Date             time.Time `gorm:"type:date;not null;uniqueIndex:idx_flow_logs_user_date"`
+		Intensity        string    `gorm:"type:enum('spotting','light','medium','heavy','clots');not null"`
+		IsIntermenstrual bool      `gorm:"not null;default:false"`
+		Note             string    `gorm:"type:varchar(255)"`
+		UserID           uint      `gorm:"not null;uniqueIndex:idx_flow_logs_user_date"`
+		MenstrualCycleID *uint     `gorm:"null;index"`
+		CreatedAt        time.Time `gorm:"autoCreateTime"`
+		UpdatedAt        time.Time `gorm:"autoUpdateTime"`
+	}
+
+	return &gormigrate.Migration{
+		ID: "20261018210000",
+
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&FlowLog{})
+		},
+
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropTable(&FlowLog{})
+		},
+	}
+}
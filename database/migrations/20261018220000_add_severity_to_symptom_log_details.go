@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"database/sql"
+
+	"github.com/go-gormigrate/gormigrate/v2"
+	"gorm.io/gorm"
+)
+
+func AddSeverityToSymptomLogDetails() *gormigrate.Migration {
+	type SymptomLogDetail struct {
+		Severity sql.NullInt16 `gorm:"type:tinyint unsigned"`
+	}
+
+	return &gormigrate.Migration{
+		ID: "20261018220000",
+
+		Migrate: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&SymptomLogDetail{})
+		},
+
+		Rollback: func(tx *gorm.DB) error {
+			return tx.Migrator().DropColumn(&SymptomLogDetail{}, "severity")
+		},
+	}
+}
@@ -1,6 +1,7 @@
 package seeders
 
 import (
+	"ipincamp/srikandi-sehat/src/constants"
 	"ipincamp/srikandi-sehat/src/models"
 	"log"
 
@@ -9,14 +10,22 @@ import (
 
 func SeedPermissions(tx *gorm.DB) error {
 	log.Println("[DB] [SEED] [PERMISSION] Seeding permissions...")
-	permissions := []models.Permission{
-		// {Name: "permission-name"},
-	}
-
-	for _, permission := range permissions {
+	permissions := make([]*models.Permission, 0, len(constants.AllPermissions))
+	for _, name := range constants.AllPermissions {
+		permission := models.Permission{Name: string(name)}
 		if err := tx.FirstOrCreate(&permission, models.Permission{Name: permission.Name}).Error; err != nil {
 			return err
 		}
+		permissions = append(permissions, &permission)
+	}
+
+	// Role admin selalu memiliki semua permission bawaan.
+	var adminRole models.Role
+	if err := tx.Where("name = ?", constants.AdminRole).First(&adminRole).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&adminRole).Association("Permissions").Append(permissions); err != nil {
+		return err
 	}
 
 	log.Println("[DB] [SEED] [PERMISSION] Permissions seeded successfully.")
@@ -0,0 +1,34 @@
+package constants
+
+// --- Batas OTP (verifikasi email & reset password) ---
+
+const (
+	OTPLength                = 6
+	OTPExpiryMinutes         = 10 // Masa berlaku kode OTP
+	OTPResendCooldownMinutes = 15 // Jeda minimal antar pengiriman OTP
+)
+
+// Kode OTP (verifikasi maupun reset password) dibatalkan setelah
+// N kali salah dan user harus meminta kode baru.
+const (
+	OTPMaxAttempts = 5
+)
+
+// --- Penguncian login (disimpan di tabel login_lockouts) ---
+
+// Akun dikunci setelah LoginMaxFailedAttempts kali gagal, dan IP setelah
+// LoginMaxFailedAttemptsPerIP kali gagal, dalam jendela LoginFailureWindowMinutes.
+// Durasi kunci berlipat dua setiap kali terkunci lagi, maksimal LoginLockoutMaxMinutes.
+const (
+	LoginMaxFailedAttempts      = 5
+	LoginMaxFailedAttemptsPerIP = 20
+	LoginFailureWindowMinutes   = 15
+	LoginLockoutBaseMinutes     = 5
+	LoginLockoutMaxMinutes      = 24 * 60
+)
+
+// Token tantangan 2FA dibatalkan setelah N kali kode salah; user harus login ulang dengan password.
+// Setiap kode yang salah juga dihitung sebagai login gagal untuk akun tersebut.
+const (
+	TwoFactorChallengeMaxAttempts = 3
+)
@@ -0,0 +1,18 @@
+package constants
+
+// ConsentType membedakan persetujuan yang diberikan user sendiri dan oleh wali.
+type ConsentType string
+
+const (
+	SelfConsent     ConsentType = "self"
+	GuardianConsent ConsentType = "guardian"
+)
+
+// CurrentConsentVersion adalah versi naskah persetujuan yang berlaku. Menaikkan versi ini
+// mewajibkan semua user (dan wali untuk user di bawah umur) memberi persetujuan ulang.
+const CurrentConsentVersion = "1.0"
+
+const (
+	DefaultGuardianConsentAge      = 18 // Umur minimal tanpa persetujuan wali (bisa diubah via GUARDIAN_CONSENT_AGE)
+	GuardianConsentLinkExpiryHours = 72
+)
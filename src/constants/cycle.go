@@ -15,6 +15,12 @@ const (
 	CycleLateThresholdDays = 32
 )
 
+// Siklus yang dihapus (soft delete) masih bisa dipulihkan user
+// selama N hari setelah dihapus.
+const (
+	CycleRestoreRetentionDays = 30
+)
+
 // --- Batas Kategori Normal (untuk UI, Laporan, & Handler) ---
 // Digunakan untuk menentukan flag IsPeriodNormal / IsCycleNormal
 
@@ -27,3 +33,99 @@ const (
 	CycleLengthMinNormalDays int16 = 21 // Kurang dari ini = Polimenorea
 	CycleLengthMaxNormalDays int16 = 35 // Lebih dari ini = Oligomenorea
 )
+
+// --- Prediksi Siklus ---
+
+const (
+	CyclePredictionMaxSamples        = 12 // Jumlah siklus terakhir yang dipakai untuk prediksi
+	CyclePredictionMinPlausibleDays  = 15 // Panjang siklus di luar rentang ini dianggap salah catat
+	CyclePredictionMaxPlausibleDays  = 90
+	CyclePredictionOutlierMADs       = 3.0 // Batas outlier dalam satuan MAD (median absolute deviation)
+	CyclePredictionDefaultStdDevDays = 3.0 // Dipakai jika data belum cukup untuk menghitung simpangan baku
+	CyclePredictionMinRangeDays      = 1   // Rentang prediksi minimal ±N hari
+	CyclePredictionDefaultPeriodDays = 5   // Lama haid prediksi jika belum ada riwayat lama haid
+)
+
+const (
+	CycleLutealPhaseDays            = 14 // Ovulasi diperkirakan N hari sebelum haid berikutnya
+	CycleFertileDaysBeforeOvulation = 5
+	CycleFertileDaysAfterOvulation  = 1
+)
+
+// Dengan prediksi, haid dianggap terlambat jika belum dimulai
+// N hari setelah batas akhir rentang prediksi.
+const (
+	CycleLatePredictionGraceDays = 7
+)
+
+// --- Kalender ---
+
+type CalendarDayState string
+
+const (
+	CalendarDayPeriod          CalendarDayState = "period"
+	CalendarDayPredictedPeriod CalendarDayState = "predicted_period"
+	CalendarDayOvulation       CalendarDayState = "ovulation"
+	CalendarDayFertile         CalendarDayState = "fertile"
+	CalendarDayNone            CalendarDayState = "none"
+)
+
+// Jumlah maksimum siklus prediksi yang diproyeksikan ke depan untuk kalender.
+const (
+	CalendarMaxPredictedCycles = 12
+)
+
+// --- Analitik Keteraturan Siklus ---
+
+const (
+	CycleAnalyticsDefaultMonths  = 12 // Jendela analitik default (bulan)
+	CycleAnalyticsMinCycles      = 3  // Minimal jumlah panjang siklus untuk klasifikasi
+	CycleRegularMaxVariationDays = 7  // Selisih siklus terpanjang-terpendek <= N hari = teratur
+	CycleHighlyIrregularDays     = 20 // Selisih >= N hari = sangat tidak teratur
+	CycleHighVariabilityStdDev   = 7.0
+	CyclePatternMinOccurrences   = 3  // Pola dianggap muncul jika terjadi di >= N siklus dalam jendela
+	CyclePatternMinConsecutive   = 2  // ...atau di >= N siklus berturut-turut
+	CycleAmenorrheaThresholdDays = 90 // Tidak haid selama > N hari
+)
+
+type CycleRegularity string
+
+const (
+	CycleRegularityInsufficientData CycleRegularity = "insufficient_data"
+	CycleRegularityRegular          CycleRegularity = "regular"
+	CycleRegularityIrregular        CycleRegularity = "irregular"
+	CycleRegularityHighlyIrregular  CycleRegularity = "highly_irregular"
+)
+
+type CyclePatternFlag string
+
+const (
+	CyclePatternOligomenorrhea  CyclePatternFlag = "recurrent_oligomenorrhea" // Siklus > CycleLengthMaxNormalDays
+	CyclePatternPolymenorrhea   CyclePatternFlag = "recurrent_polymenorrhea"  // Siklus < CycleLengthMinNormalDays
+	CyclePatternMenorrhagia     CyclePatternFlag = "recurrent_menorrhagia"    // Haid > CyclePeriodMaxNormalDays
+	CyclePatternHypomenorrhea   CyclePatternFlag = "recurrent_hypomenorrhea"  // Haid < CyclePeriodMinNormalDays
+	CyclePatternHighVariability CyclePatternFlag = "high_cycle_variability"   // Simpangan baku > CycleHighVariabilityStdDev
+	CyclePatternAmenorrhea      CyclePatternFlag = "possible_amenorrhea"      // Tidak haid > CycleAmenorrheaThresholdDays
+)
+
+// --- Intensitas Aliran Haid ---
+
+type FlowIntensity string
+
+const (
+	FlowSpotting FlowIntensity = "spotting"
+	FlowLight    FlowIntensity = "light"
+	FlowMedium   FlowIntensity = "medium"
+	FlowHeavy    FlowIntensity = "heavy"
+	FlowClots    FlowIntensity = "clots" // Aliran berat disertai gumpalan
+)
+
+// FlowIntensityLevels berurutan dari yang paling ringan.
+var FlowIntensityLevels = []FlowIntensity{FlowSpotting, FlowLight, FlowMedium, FlowHeavy, FlowClots}
+
+// Ringkasan aliran menandai kemungkinan perdarahan berat (risiko menoragia/anemia)
+// jika hari 'heavy'/'clots' dalam satu siklus mencapai N hari, atau gumpalan muncul di >= N hari.
+const (
+	FlowHeavyDaysThreshold = 3
+	FlowClotDaysThreshold  = 2
+)
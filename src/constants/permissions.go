@@ -0,0 +1,22 @@
+package constants
+
+type PermissionName string
+
+// Permission untuk rute /api/admin. Role admin mendapat semua permission ini lewat seeder/migrasi;
+// role lain bisa diberi sebagian lewat API manajemen role.
+const (
+	UsersReadPermission         PermissionName = "users.read"
+	UsersManagePermission       PermissionName = "users.manage"
+	RolesManagePermission       PermissionName = "roles.manage"
+	ReportsExportPermission     PermissionName = "reports.export"
+	MaintenanceManagePermission PermissionName = "maintenance.manage"
+)
+
+// AllPermissions adalah daftar permission bawaan yang di-seed.
+var AllPermissions = []PermissionName{
+	UsersReadPermission,
+	UsersManagePermission,
+	RolesManagePermission,
+	ReportsExportPermission,
+	MaintenanceManagePermission,
+}
@@ -8,6 +8,16 @@ const (
 	SymptomTypeOptions SymptomType = "OPTIONS"
 )
 
+// Skala keparahan gejala (0 = tidak terasa, 10 = sangat berat/mengganggu aktivitas).
+// Saat menyusun rekomendasi, setiap kemunculan gejala diberi bobot
+// SymptomSeverityBaseWeight + severity, sehingga gejala berat lebih diprioritaskan
+// daripada gejala ringan yang sering muncul. Gejala tanpa severity (termasuk log lama)
+// dianggap bernilai tengah skala, bukan 0 ("tidak terasa").
+const (
+	SymptomSeverityBaseWeight = 5
+	SymptomSeverityDefault    = 5
+)
+
 const (
 	MoodTypeHappy   MoodType = "happy"
 	MoodTypeNeutral MoodType = "neutral"
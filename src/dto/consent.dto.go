@@ -0,0 +1,83 @@
+package dto
+
+import (
+	"ipincamp/srikandi-sehat/src/constants"
+	"ipincamp/srikandi-sehat/src/models"
+	"time"
+)
+
+// --- Request Params ---
+type GuardianConsentParam struct {
+	Token string `params:"token" validate:"required,max=64"`
+}
+
+// --- Request Body ---
+
+// GiveConsentRequest: data wali wajib diisi jika user berada di bawah batas umur.
+type GiveConsentRequest struct {
+	Agree         bool    `json:"agree" validate:"required"`
+	GuardianName  *string `json:"guardian_name" validate:"omitempty,min=3,max=100"`
+	GuardianEmail *string `json:"guardian_email" validate:"omitempty,email,max=255"`
+}
+
+// --- Response Body ---
+type ConsentResponse struct {
+	ID            string                `json:"id"`
+	Version       string                `json:"version"`
+	Type          constants.ConsentType `json:"type"`
+	Status        string                `json:"status"`
+	GuardianName  *string               `json:"guardian_name,omitempty"`
+	GuardianEmail *string               `json:"guardian_email,omitempty"`
+	RequestedAt   time.Time             `json:"requested_at"`
+	LinkExpiresAt *time.Time            `json:"link_expires_at,omitempty"`
+	GrantedAt     *time.Time            `json:"granted_at,omitempty"`
+	RevokedAt     *time.Time            `json:"revoked_at,omitempty"`
+}
+
+type ConsentStatusResponse struct {
+	RequiredVersion  string           `json:"required_version"`
+	ConsentGranted   bool             `json:"consent_granted"`
+	RequiresGuardian bool             `json:"requires_guardian"`
+	GuardianAgeLimit int              `json:"guardian_age_limit"`
+	Consent          *ConsentResponse `json:"consent"`
+}
+
+// ConsentStatus menentukan status persetujuan: granted, revoked, expired, atau pending.
+func ConsentStatus(consent models.Consent) string {
+	switch {
+	case consent.RevokedAt.Valid:
+		return "revoked"
+	case consent.GrantedAt.Valid:
+		return "granted"
+	case consent.TokenExpiresAt.Valid && time.Now().After(consent.TokenExpiresAt.Time):
+		return "expired"
+	default:
+		return "pending"
+	}
+}
+
+func ConsentResponseJson(consent models.Consent) ConsentResponse {
+	response := ConsentResponse{
+		ID:          consent.UUID,
+		Version:     consent.Version,
+		Type:        consent.Type,
+		Status:      ConsentStatus(consent),
+		RequestedAt: consent.CreatedAt,
+	}
+	if consent.GuardianName.Valid {
+		response.GuardianName = &consent.GuardianName.String
+	}
+	if consent.GuardianEmail.Valid {
+		response.GuardianEmail = &consent.GuardianEmail.String
+	}
+	if consent.TokenExpiresAt.Valid && !consent.GrantedAt.Valid {
+		response.LinkExpiresAt = &consent.TokenExpiresAt.Time
+	}
+	if consent.GrantedAt.Valid {
+		response.GrantedAt = &consent.GrantedAt.Time
+	}
+	if consent.RevokedAt.Valid {
+		response.RevokedAt = &consent.RevokedAt.Time
+	}
+	return response
+}
@@ -46,12 +46,60 @@ type PaginationQuery struct {
 	Limit int `query:"limit" validate:"omitempty,numeric,min=1"`
 }
 
+// CalendarQuery: tz adalah nama zona waktu IANA (misalnya Asia/Makassar); default zona waktu aplikasi.
+type CalendarQuery struct {
+	Month    string `query:"month" validate:"required,datetime=2006-01"`
+	Timezone string `query:"tz" validate:"omitempty,timezone"`
+}
+
+// CycleAnalyticsQuery: months adalah jendela analitik (default 12 bulan).
+type CycleAnalyticsQuery struct {
+	Months int `query:"months" validate:"omitempty,numeric,min=6,max=12"`
+}
+
+type FlowLogParam struct {
+	ID uint `params:"id" validate:"required,numeric"`
+}
+
+type FlowLogQuery struct {
+	Page               int    `query:"page" validate:"omitempty,numeric,min=1"`
+	Limit              int    `query:"limit" validate:"omitempty,numeric,min=1"`
+	StartDate          string `query:"start_date" validate:"omitempty,datetime=2006-01-02"`
+	EndDate            string `query:"finish_date" validate:"omitempty,datetime=2006-01-02"`
+	IntermenstrualOnly bool   `query:"intermenstrual"`
+}
+
 // Request Body
+
+// FlowLogRequest mencatat intensitas aliran untuk satu hari. Mencatat ulang hari yang sama akan menimpa entri sebelumnya.
+type FlowLogRequest struct {
+	Date      string `json:"date" validate:"required,datetime=2006-01-02"`
+	Intensity string `json:"intensity" validate:"required,oneof=spotting light medium heavy clots"`
+	Note      string `json:"note" validate:"omitempty,max=255"`
+}
+
 type CycleRequest struct {
 	StartDate string `json:"start_date" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
 	EndDate   string `json:"finish_date" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
 }
 
+// UpdateCycleRequest mengoreksi tanggal mulai dan/atau selesai siklus yang sudah tercatat.
+type UpdateCycleRequest struct {
+	StartDate string `json:"start_date" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+	EndDate   string `json:"finish_date" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+// ImportCycleEntry adalah satu periode lama (tanggal mulai dan selesai) yang diingat user.
+type ImportCycleEntry struct {
+	StartDate string `json:"start_date" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
+	EndDate   string `json:"finish_date" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+// ImportCyclesRequest dibatasi 24 periode (sekitar dua tahun) per permintaan.
+type ImportCyclesRequest struct {
+	Cycles []ImportCycleEntry `json:"cycles" validate:"required,min=1,max=24,dive"`
+}
+
 type DeleteCycleRequest struct {
 	Reason string `json:"reason" validate:"required,min=5,max=255"`
 }
@@ -67,23 +115,43 @@ type CycleResponse struct {
 	IsCycleNormal  *bool      `json:"is_cycle_normal,omitempty"`
 }
 
+type DeleteCycleResponse struct {
+	RestorableUntil time.Time `json:"restorable_until"`
+}
+
 type CycleStatusResponse struct {
-	IsOnCycle           bool    `json:"is_on_cycle"`
-	CurrentPeriodDay    *int    `json:"current_period_day,omitempty"`
-	IsPeriodNormal      *bool   `json:"is_period_normal,omitempty"`
-	LastPeriodLength    *int    `json:"last_period_length,omitempty"`
-	CurrentCycleLength  *int    `json:"current_cycle_length,omitempty"`
-	LastCycleLength     *int    `json:"last_cycle_length,omitempty"`
-	IsCycleNormal       *bool   `json:"is_cycle_normal,omitempty"`
-	DaysUntilNextPeriod *int    `json:"days_until_next_period,omitempty"`
-	PredictedPeriodDate *string `json:"predicted_period_date,omitempty"`
-	Message             string  `json:"message"`
+	IsOnCycle           bool                     `json:"is_on_cycle"`
+	CurrentPeriodDay    *int                     `json:"current_period_day,omitempty"`
+	IsPeriodNormal      *bool                    `json:"is_period_normal,omitempty"`
+	LastPeriodLength    *int                     `json:"last_period_length,omitempty"`
+	CurrentCycleLength  *int                     `json:"current_cycle_length,omitempty"`
+	LastCycleLength     *int                     `json:"last_cycle_length,omitempty"`
+	IsCycleNormal       *bool                    `json:"is_cycle_normal,omitempty"`
+	DaysUntilNextPeriod *int                     `json:"days_until_next_period,omitempty"`
+	PredictedPeriodDate *string                  `json:"predicted_period_date,omitempty"`
+	Prediction          *CyclePredictionResponse `json:"prediction,omitempty"`
+	Message             string                   `json:"message"`
+}
+
+// CyclePredictionResponse merinci prediksi haid berikutnya. Tanggal berformat YYYY-MM-DD.
+type CyclePredictionResponse struct {
+	PredictedPeriodDate string  `json:"predicted_period_date"`
+	EarliestPeriodDate  string  `json:"earliest_period_date"`
+	LatestPeriodDate    string  `json:"latest_period_date"`
+	OvulationDate       string  `json:"ovulation_date"`
+	FertileWindowStart  string  `json:"fertile_window_start"`
+	FertileWindowEnd    string  `json:"fertile_window_end"`
+	AverageCycleLength  float64 `json:"average_cycle_length"`
+	CycleLengthStdDev   float64 `json:"cycle_length_std_dev"`
+	SampleSize          int     `json:"sample_size"`
+	ExcludedOutliers    int     `json:"excluded_outliers"`
 }
 
 type SymptomDetail struct {
 	SymptomName     string  `json:"symptom_name"`
 	SymptomCategory string  `json:"symptom_category"`
 	SelectedOption  *string `json:"selected_option,omitempty"`
+	Severity        *int16  `json:"severity,omitempty"`
 }
 
 type SymptomLogGroupResponse struct {
@@ -101,5 +169,66 @@ type CycleDetailResponse struct {
 	CycleLength    *int16                    `json:"cycle_length,omitempty"`
 	IsPeriodNormal *bool                     `json:"is_period_normal,omitempty"`
 	IsCycleNormal  *bool                     `json:"is_cycle_normal,omitempty"`
+	FlowSummary    *FlowSummaryResponse      `json:"flow_summary,omitempty"`
 	Symptoms       []SymptomLogGroupResponse `json:"symptoms"`
 }
+
+type FlowLogResponse struct {
+	ID               uint    `json:"id"`
+	Date             string  `json:"date"`
+	Intensity        string  `json:"intensity"`
+	IsIntermenstrual bool    `json:"is_intermenstrual"`
+	CycleID          *int64  `json:"cycle_id,omitempty"`
+	Note             *string `json:"note,omitempty"`
+}
+
+// FlowSummaryResponse merangkum aliran haid dalam satu siklus.
+type FlowSummaryResponse struct {
+	LoggedDays            int            `json:"logged_days"`
+	DaysByIntensity       map[string]int `json:"days_by_intensity"`
+	PeakIntensity         string         `json:"peak_intensity"`
+	HeavyDays             int            `json:"heavy_days"`
+	ClotDays              int            `json:"clot_days"`
+	PossibleHeavyBleeding bool           `json:"possible_heavy_bleeding"`
+}
+
+type CalendarDayResponse struct {
+	Date          string `json:"date"`
+	State         string `json:"state"`
+	CycleID       *uint  `json:"cycle_id,omitempty"`
+	HasSymptoms   bool   `json:"has_symptoms"`
+	SymptomLogIDs []uint `json:"symptom_log_ids"`
+}
+
+type CalendarResponse struct {
+	Month    string                `json:"month"`
+	Timezone string                `json:"timezone"`
+	Days     []CalendarDayResponse `json:"days"`
+}
+
+type LengthStatsResponse struct {
+	Count  int      `json:"count"`
+	Mean   *float64 `json:"mean,omitempty"`
+	StdDev *float64 `json:"std_dev,omitempty"`
+	Min    *int16   `json:"min,omitempty"`
+	Max    *int16   `json:"max,omitempty"`
+}
+
+type CyclePatternFlagResponse struct {
+	Code        string `json:"code"`
+	Description string `json:"description"`
+	Occurrences int    `json:"occurrences"`
+	LongestRun  int    `json:"longest_run"`
+}
+
+type CycleAnalyticsResponse struct {
+	Months               int                        `json:"months"`
+	From                 time.Time                  `json:"from"`
+	CycleCount           int                        `json:"cycle_count"`
+	CycleLength          LengthStatsResponse        `json:"cycle_length"`
+	PeriodLength         LengthStatsResponse        `json:"period_length"`
+	CycleLengthVariation *int16                     `json:"cycle_length_variation,omitempty"`
+	Regularity           string                     `json:"regularity"`
+	DaysSinceLastPeriod  *int                       `json:"days_since_last_period,omitempty"`
+	Flags                []CyclePatternFlagResponse `json:"flags"`
+}
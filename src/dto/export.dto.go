@@ -0,0 +1,92 @@
+package dto
+
+import "time"
+
+// --- Personal Data Export (data portability) ---
+// Struktur ini ditulis sebagai file JSON di dalam arsip ZIP yang diunduh user.
+
+type ExportRegion struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+type ExportRegionPath struct {
+	Province       ExportRegion `json:"province"`
+	Regency        ExportRegion `json:"regency"`
+	District       ExportRegion `json:"district"`
+	Village        ExportRegion `json:"village"`
+	Classification string       `json:"classification"`
+}
+
+type ExportAccount struct {
+	ID              string            `json:"id"`
+	Name            string            `json:"name"`
+	Email           string            `json:"email"`
+	EmailVerifiedAt *time.Time        `json:"email_verified_at"`
+	RegisteredAt    time.Time         `json:"registered_at"`
+	Profile         *ProfileResponse  `json:"profile"`
+	Region          *ExportRegionPath `json:"region"`
+}
+
+type ExportCycle struct {
+	ID             uint       `json:"id"`
+	StartDate      time.Time  `json:"start_date"`
+	EndDate        *time.Time `json:"end_date"`
+	PeriodLength   *int16     `json:"period_length_days"`
+	CycleLength    *int16     `json:"cycle_length_days"`
+	IsPeriodNormal *bool      `json:"is_period_normal"`
+	IsCycleNormal  *bool      `json:"is_cycle_normal"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeletedAt      *time.Time `json:"deleted_at"`
+	DeletionReason *string    `json:"deletion_reason"`
+}
+
+type ExportSymptomDetail struct {
+	Symptom     string  `json:"symptom"`
+	Category    string  `json:"category"`
+	OptionName  *string `json:"option_name"`
+	OptionValue *string `json:"option_value"`
+	Severity    *int16  `json:"severity"`
+}
+
+type ExportSymptomLog struct {
+	ID               uint                  `json:"id"`
+	LoggedAt         time.Time             `json:"logged_at"`
+	Note             string                `json:"note"`
+	MenstrualCycleID *int64                `json:"menstrual_cycle_id"`
+	Details          []ExportSymptomDetail `json:"details"`
+	CreatedAt        time.Time             `json:"created_at"`
+}
+
+type ExportNotification struct {
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	IsRead    bool      `json:"is_read"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ExportFlowLog struct {
+	Date             string    `json:"date"`
+	Intensity        string    `json:"intensity"`
+	IsIntermenstrual bool      `json:"is_intermenstrual"`
+	Note             string    `json:"note"`
+	MenstrualCycleID *int64    `json:"menstrual_cycle_id"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// ExportConsent adalah riwayat persetujuan user, termasuk data wali untuk pengguna di bawah umur.
+type ExportConsent struct {
+	Version       string     `json:"version"`
+	Type          string     `json:"type"`
+	GuardianName  *string    `json:"guardian_name,omitempty"`
+	GuardianEmail *string    `json:"guardian_email,omitempty"`
+	RequestedAt   time.Time  `json:"requested_at"`
+	GrantedAt     *time.Time `json:"granted_at"`
+	RevokedAt     *time.Time `json:"revoked_at"`
+}
+
+// PersonalDataExportResponse adalah respons saat user meminta ekspor data pribadi.
+type PersonalDataExportResponse struct {
+	DownloadURL string    `json:"download_url"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
@@ -0,0 +1,92 @@
+package dto
+
+import (
+	"ipincamp/srikandi-sehat/src/models"
+	"time"
+)
+
+// --- Request Params ---
+type RoleParam struct {
+	ID uint `params:"id" validate:"required,numeric"`
+}
+
+type PermissionParam struct {
+	ID uint `params:"id" validate:"required,numeric"`
+}
+
+type UserRoleParam struct {
+	ID   string `params:"id" validate:"required,uuid"`
+	Role string `params:"role" validate:"required,max=100"`
+}
+
+type UserPermissionParam struct {
+	ID         string `params:"id" validate:"required,uuid"`
+	Permission string `params:"permission" validate:"required,max=100"`
+}
+
+// --- Request Body ---
+
+// CreateRoleRequest: RegionCode (kode provinsi, kabupaten/kota, kecamatan, atau desa) membuat role berwilayah,
+// misalnya untuk bidan/kader puskesmas atau guru UKS yang hanya boleh melihat user di wilayahnya.
+type CreateRoleRequest struct {
+	Name        string   `json:"name" validate:"required,min=2,max=100,access_name"`
+	Permissions []string `json:"permissions" validate:"omitempty,dive,required,max=100"`
+	RegionCode  string   `json:"region_code" validate:"omitempty,numeric,max=10"`
+}
+
+// UpdateRoleRequest: Permissions (jika dikirim) menggantikan seluruh permission role.
+// RegionCode berisi string kosong untuk menghapus batas wilayah role.
+type UpdateRoleRequest struct {
+	Name        *string   `json:"name" validate:"omitempty,min=2,max=100,access_name"`
+	Permissions *[]string `json:"permissions" validate:"omitempty,dive,required,max=100"`
+	RegionCode  *string   `json:"region_code" validate:"omitempty,numeric,max=10"`
+}
+
+type PermissionRequest struct {
+	Name string `json:"name" validate:"required,min=2,max=100,access_name"`
+}
+
+type AssignRoleRequest struct {
+	Role string `json:"role" validate:"required,max=100"`
+}
+
+type AssignPermissionRequest struct {
+	Permission string `json:"permission" validate:"required,max=100"`
+}
+
+// --- Response Body ---
+type PermissionResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type RoleRegionScope struct {
+	Level string `json:"level"`
+	Code  string `json:"code"`
+}
+
+type RoleResponse struct {
+	ID          uint             `json:"id"`
+	Name        string           `json:"name"`
+	RegionScope *RoleRegionScope `json:"region_scope"`
+	Permissions []string         `json:"permissions"`
+	UserCount   int64            `json:"user_count"`
+	IsSystem    bool             `json:"is_system"`
+	CreatedAt   time.Time        `json:"created_at"`
+}
+
+type UserAccessResponse struct {
+	UserID               string   `json:"user_id"`
+	Roles                []string `json:"roles"`
+	DirectPermissions    []string `json:"direct_permissions"`
+	EffectivePermissions []string `json:"effective_permissions"`
+}
+
+func PermissionResponseJson(permission models.Permission) PermissionResponse {
+	return PermissionResponse{
+		ID:        permission.ID,
+		Name:      permission.Name,
+		CreatedAt: permission.CreatedAt,
+	}
+}
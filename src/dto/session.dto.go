@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+// --- Request Params ---
+type SessionParam struct {
+	ID string `params:"id" validate:"required,uuid"`
+}
+
+// --- Response Body ---
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	DeviceName string    `json:"device_name"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	IsCurrent  bool      `json:"is_current"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
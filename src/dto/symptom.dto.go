@@ -27,12 +27,13 @@ type SymptomHistoryQuery struct {
 type SymptomLogDetailRequest struct {
 	SymptomID       uint  `json:"symptom_id" validate:"required"`
 	SymptomOptionID *uint `json:"option_id,omitempty"`
+	Severity        *int  `json:"severity,omitempty" validate:"omitempty,min=0,max=10"`
 }
 
 type SymptomLogRequest struct {
 	LoggedAt string                    `json:"logged_at" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
 	Note     string                    `json:"note" validate:"omitempty"`
-	Symptoms []SymptomLogDetailRequest `json:"symptoms" validate:"required,min=1"`
+	Symptoms []SymptomLogDetailRequest `json:"symptoms" validate:"required,min=1,dive"`
 }
 
 // --- Response Body ---
@@ -57,6 +58,7 @@ type SymptomLogDetailResponse struct {
 	SymptomName     string `json:"symptom_name"`
 	SymptomCategory string `json:"symptom_category"`
 	SelectedOption  string `json:"selected_option,omitempty"`
+	Severity        *int16 `json:"severity,omitempty"`
 }
 
 type SymptomLogDetailViewResponse struct {
@@ -41,6 +41,15 @@ type ChangePasswordRequest struct {
 	NewPasswordConfirmation string `json:"new_password_confirmation" validate:"required,eqfield=NewPassword"`
 }
 
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" validate:"required,email"`
+	Password string `json:"password" validate:"required"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
 // --- Response Body ---
 type CycleHistoryEntry struct {
 	ID               uint       `json:"id"`
@@ -73,13 +82,26 @@ type UserResponse struct {
 	Email             string              `json:"email"`
 	Role              string              `json:"role,omitempty"`
 	Token             string              `json:"token,omitempty"`
+	RefreshToken      string              `json:"refresh_token,omitempty"`
+	RecoveryCodes     []string            `json:"recovery_codes,omitempty"`
 	IsProfileComplete bool                `json:"profile_complete"`
 	IsVerified        bool                `json:"is_verified"`
 	Profile           *ProfileResponse    `json:"profile,omitempty"`
 	CycleHistory      []CycleHistoryEntry `json:"cycle_history,omitempty"`
+	DeletionScheduled *time.Time          `json:"deletion_scheduled_at,omitempty"`
 	CreatedAt         time.Time           `json:"created_at"`
 }
 
+type EmailChangeResponse struct {
+	PendingEmail string    `json:"pending_email"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+type AccountDeletionResponse struct {
+	RequestedAt time.Time `json:"requested_at"`
+	ScheduledAt time.Time `json:"scheduled_at"`
+}
+
 type UserStatisticsResponse struct {
 	TotalRuralUsers  int64 `json:"total_rural_users"`
 	TotalUrbanUsers  int64 `json:"total_urban_users"`
@@ -87,6 +109,21 @@ type UserStatisticsResponse struct {
 	TotalUsers       int64 `json:"total_users"`
 }
 
+type PasswordHashGroup struct {
+	Version   int    `json:"version"` // 0 = tidak sesuai kebijakan mana pun
+	Algorithm string `json:"algorithm"`
+	Params    string `json:"params"`
+	Users     int64  `json:"users"`
+	IsCurrent bool   `json:"is_current"`
+}
+
+type PasswordHashReportResponse struct {
+	CurrentVersion int                 `json:"current_version"`
+	TotalUsers     int64               `json:"total_users"`
+	OutdatedUsers  int64               `json:"outdated_users"`
+	Groups         []PasswordHashGroup `json:"groups"`
+}
+
 type UserCSVRecord struct {
 	UUID                string    `json:"uuid"`
 	Name                string    `json:"name"`
@@ -147,6 +184,11 @@ func UserResponseJson(user models.User, token ...string) UserResponse {
 
 	isVerified := user.EmailVerifiedAt.Valid
 
+	var deletionScheduled *time.Time
+	if user.DeletionScheduledAt.Valid {
+		deletionScheduled = &user.DeletionScheduledAt.Time
+	}
+
 	var response UserResponse
 	if len(token) > 0 {
 		response = UserResponse{
@@ -157,6 +199,7 @@ func UserResponseJson(user models.User, token ...string) UserResponse {
 			Token:             token[0],
 			IsProfileComplete: isProfileComplete,
 			IsVerified:        isVerified,
+			DeletionScheduled: deletionScheduled,
 			CreatedAt:         user.CreatedAt,
 		}
 	} else {
@@ -168,6 +211,7 @@ func UserResponseJson(user models.User, token ...string) UserResponse {
 			IsProfileComplete: isProfileComplete,
 			IsVerified:        isVerified,
 			Profile:           profileData,
+			DeletionScheduled: deletionScheduled,
 			CreatedAt:         user.CreatedAt,
 		}
 	}
@@ -41,6 +41,72 @@ func isDomainAllowed(email string) bool {
 	return allowedDomainsMap[domain]
 }
 
+// otpResendWaitMessage mengembalikan pesan tunggu jika OTP terakhir (verifikasi maupun reset password)
+// dikirim kurang dari OTPResendCooldownMinutes yang lalu. String kosong berarti OTP baru boleh dikirim.
+func otpResendWaitMessage(user models.User) string {
+	if !user.LastOTPSentAt.Valid {
+		return ""
+	}
+
+	// Tentukan kapan user boleh request lagi
+	nextAllowedTime := user.LastOTPSentAt.Time.Add(constants.OTPResendCooldownMinutes * time.Minute)
+	if !time.Now().Before(nextAllowedTime) {
+		return ""
+	}
+
+	// Format sisa waktu agar lebih ramah
+	remaining := time.Until(nextAllowedTime)
+	remainingMinutes := int(remaining.Minutes())
+	remainingSeconds := int(remaining.Seconds()) % 60
+
+	return fmt.Sprintf(
+		"Harap tunggu %d menit %d detik lagi sebelum meminta kode baru.",
+		remainingMinutes,
+		remainingSeconds,
+	)
+}
+
+// loginLockedMessage membuat pesan untuk akun atau IP yang sedang dikunci.
+func loginLockedMessage(lockedUntil time.Time) string {
+	remainingMinutes := int(time.Until(lockedUntil).Minutes()) + 1
+	return fmt.Sprintf("Terlalu banyak percobaan login yang gagal. Silakan coba lagi dalam %d menit.", remainingMinutes)
+}
+
+// recordIPLoginFailure mencatat login gagal untuk alamat IP. IP dikunci terpisah dari akun
+// agar percobaan ke banyak email berbeda dari satu IP tetap tertahan.
+func recordIPLoginFailure(ip string) {
+	lockedUntil, locked, err := utils.RecordLoginFailure(models.LoginLockoutScopeIP, ip, constants.LoginMaxFailedAttemptsPerIP)
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to record login failure for IP %s: %v", ip, err)
+		return
+	}
+	if locked {
+		utils.AuthLogger.Printf("IP locked until %s after repeated failed logins: %s", lockedUntil.Format(time.RFC3339), ip)
+	}
+}
+
+// upgradePasswordHash meng-hash ulang password dengan kebijakan aktif jika hash user
+// masih memakai parameter lama atau algoritma lama. Kegagalan hanya dicatat karena login tetap sah.
+func upgradePasswordHash(user *models.User, password string) {
+	if !utils.PasswordNeedsRehash(user.Password) {
+		return
+	}
+
+	previous := utils.InspectPasswordHash(user.Password)
+	newHash, err := utils.HashPassword(password)
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to rehash password for %s: %v", user.UUID, err)
+		return
+	}
+	if err := database.DB.Model(user).Update("password", newHash).Error; err != nil {
+		utils.ErrorLogger.Printf("Failed to store upgraded password hash for %s: %v", user.UUID, err)
+		return
+	}
+
+	utils.AuthLogger.Printf("Password hash upgraded (%s %s -> policy v%d): %s",
+		previous.Algorithm, previous.Params, utils.CurrentPasswordHashPolicy().Version, user.UUID)
+}
+
 func Register(c *fiber.Ctx) error {
 	input := c.Locals("request_body").(*dto.RegisterRequest)
 
@@ -96,15 +162,16 @@ func Register(c *fiber.Ctx) error {
 	user.Roles = []*models.Role{&defaultRole}
 	user.Profile = models.Profile{} // Profile baru, ID-nya 0
 
-	token, err := utils.GenerateJWT(user)
+	token, refreshToken, err := utils.CreateSession(user, input.DeviceName, c.Get(fiber.HeaderUserAgent), c.IP())
 	if err != nil {
-		utils.ErrorLogger.Printf("Gagal membuat JWT untuk user baru %s: %v", user.Email, err)
+		utils.ErrorLogger.Printf("Gagal membuat sesi untuk user baru %s: %v", user.Email, err)
 		return utils.SendError(c, fiber.StatusInternalServerError, "Gagal memproses sesi login")
 	}
 
 	utils.AddEmailToRegistrationFilter(user.Email)
 
 	responseData := dto.UserResponseJson(user, token)
+	responseData.RefreshToken = refreshToken
 
 	utils.AuthLogger.Printf("User registered successfully: %s (UUID: %s)", responseData.Email, responseData.ID)
 	return utils.SendSuccess(c, fiber.StatusCreated, "Registrasi sukses! Silakan verifikasi email Anda untuk mendapatkan akses penuh.", responseData)
@@ -140,8 +207,27 @@ func VerifyOTP(c *fiber.Ctx) error {
 		return utils.SendError(c, fiber.StatusForbidden, "Kode OTP telah kedaluwarsa. Silakan minta kirim ulang.")
 	}
 
-	// Cek 4: Apakah OTP cocok?
-	if user.VerificationToken.String != input.OTP {
+	// Cek 4: Catat percobaan secara atomik sebelum membandingkan kode.
+	allowed, err := utils.ConsumeOTPAttempt(user.ID, "verification_token", user.VerificationToken.String, "verification_attempts")
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to record OTP attempt for %s: %v", userUUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Gagal memverifikasi akun")
+	}
+	if !allowed {
+		// Batalkan OTP; user harus meminta kode baru (tetap mengikuti jeda kirim ulang).
+		if err := database.DB.Model(&user).Where("verification_token = ?", user.VerificationToken.String).Updates(map[string]interface{}{
+			"verification_token":      nil,
+			"verification_expires_at": nil,
+			"verification_attempts":   0,
+		}).Error; err != nil {
+			utils.ErrorLogger.Printf("Failed to cancel OTP for %s: %v", userUUID, err)
+		}
+		utils.AuthLogger.Printf("OTP verification locked out after %d failed attempts: %s", constants.OTPMaxAttempts, userUUID)
+		return utils.SendError(c, fiber.StatusTooManyRequests, "Terlalu banyak percobaan. Kode OTP dibatalkan, silakan minta kirim ulang.")
+	}
+
+	// Cek 5: Apakah OTP cocok?
+	if !utils.CheckOTP(input.OTP, user.VerificationToken.String) {
 		utils.AuthLogger.Printf("OTP verification failed (wrong OTP): %s", userUUID)
 		return utils.SendError(c, fiber.StatusUnauthorized, "Kode OTP salah.")
 	}
@@ -151,6 +237,7 @@ func VerifyOTP(c *fiber.Ctx) error {
 		"email_verified_at":       time.Now(),
 		"verification_token":      nil,
 		"verification_expires_at": nil,
+		"verification_attempts":   0,
 	}
 
 	if err := database.DB.Model(&user).Updates(updates).Error; err != nil {
@@ -173,40 +260,30 @@ func ResendVerification(c *fiber.Ctx) error {
 		return utils.SendError(c, fiber.StatusConflict, "Email sudah terverifikasi.")
 	}
 
-	if user.LastOTPSentAt.Valid {
-		// Tentukan kapan user boleh request lagi
-		nextAllowedTime := user.LastOTPSentAt.Time.Add(15 * time.Minute)
-
-		if time.Now().Before(nextAllowedTime) {
-			// Jika sekarang SEBELUM waktu yang diizinkan, tolak request
-			remaining := time.Until(nextAllowedTime)
-
-			// Format sisa waktu agar lebih ramah
-			remainingMinutes := int(remaining.Minutes())
-			remainingSeconds := int(remaining.Seconds()) % 60
-
-			errMsg := fmt.Sprintf(
-				"Harap tunggu %d menit %d detik lagi sebelum meminta kode baru.",
-				remainingMinutes,
-				remainingSeconds,
-			)
-			utils.AuthLogger.Printf("Resend OTP failed (429 Too Many Requests): %s", userUUID)
-			// Kirim status 429 Too Many Requests
-			return utils.SendError(c, fiber.StatusTooManyRequests, errMsg)
-		}
+	if errMsg := otpResendWaitMessage(user); errMsg != "" {
+		utils.AuthLogger.Printf("Resend OTP failed (429 Too Many Requests): %s", userUUID)
+		// Kirim status 429 Too Many Requests
+		return utils.SendError(c, fiber.StatusTooManyRequests, errMsg)
 	}
 
 	// Buat 6-digit OTP, kedaluwarsa 10 Menit
-	verificationToken, err := utils.GenerateOTP(6)
+	verificationToken, err := utils.GenerateOTP(constants.OTPLength)
 	if err != nil {
 		utils.ErrorLogger.Printf("Gagal membuat OTP (resend): %v", err)
 		return utils.SendError(c, fiber.StatusInternalServerError, "Gagal memproses permintaan")
 	}
-	verificationExpires := time.Now().Add(10 * time.Minute) // 10 Menit
+	verificationExpires := time.Now().Add(constants.OTPExpiryMinutes * time.Minute)
+
+	hashedVerificationToken, err := utils.HashOTP(verificationToken)
+	if err != nil {
+		utils.ErrorLogger.Printf("Gagal hash OTP (resend) untuk %s: %v", userUUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Gagal memproses permintaan")
+	}
 
 	updates := map[string]interface{}{
-		"verification_token":      verificationToken,
+		"verification_token":      hashedVerificationToken,
 		"verification_expires_at": verificationExpires,
+		"verification_attempts":   0,
 		"last_otp_sent_at":        time.Now(),
 	}
 
@@ -224,13 +301,147 @@ func ResendVerification(c *fiber.Ctx) error {
 	return utils.SendSuccess(c, fiber.StatusOK, "Kode OTP baru telah dikirim ke email Anda.", nil)
 }
 
+func ForgotPassword(c *fiber.Ctx) error {
+	input := c.Locals("request_body").(*dto.ForgotPasswordRequest)
+	// Pesan yang sama untuk email terdaftar maupun tidak, agar email tidak bisa ditebak.
+	successMessage := "Jika email terdaftar, kode reset password telah dikirim ke email tersebut."
+
+	var user models.User
+	err := database.DB.First(&user, "email = ?", input.Email).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.AuthLogger.Printf("Forgot password requested for unknown email: %s", input.Email)
+		return utils.SendSuccess(c, fiber.StatusOK, successMessage, nil)
+	}
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Database query error")
+	}
+
+	if errMsg := otpResendWaitMessage(user); errMsg != "" {
+		utils.AuthLogger.Printf("Forgot password failed (429 Too Many Requests): %s", user.UUID)
+		return utils.SendError(c, fiber.StatusTooManyRequests, errMsg)
+	}
+
+	resetToken, err := utils.GenerateOTP(constants.OTPLength)
+	if err != nil {
+		utils.ErrorLogger.Printf("Gagal membuat OTP (reset password): %v", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Gagal memproses permintaan")
+	}
+	hashedResetToken, err := utils.HashOTP(resetToken)
+	if err != nil {
+		utils.ErrorLogger.Printf("Gagal hash OTP (reset password) untuk %s: %v", user.UUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Gagal memproses permintaan")
+	}
+	resetExpires := time.Now().Add(constants.OTPExpiryMinutes * time.Minute)
+
+	updates := map[string]interface{}{
+		"password_reset_token":      hashedResetToken,
+		"password_reset_expires_at": resetExpires,
+		"password_reset_attempts":   0,
+		"last_otp_sent_at":          time.Now(),
+	}
+
+	if err := database.DB.Model(&user).Updates(updates).Error; err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Gagal memperbarui token")
+	}
+
+	if err := utils.SendPasswordResetOTPEmail(user.Email, resetToken, resetExpires); err != nil {
+		utils.ErrorLogger.Printf("Gagal mengirim OTP reset password ke %s: %v", user.Email, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Gagal mengirim email reset password.")
+	}
+
+	utils.AuthLogger.Printf("Password reset OTP sent: %s", user.UUID)
+	return utils.SendSuccess(c, fiber.StatusOK, successMessage, nil)
+}
+
+func ResetPassword(c *fiber.Ctx) error {
+	input := c.Locals("request_body").(*dto.ResetPasswordRequest)
+	invalidMessage := "Kode OTP salah atau tidak berlaku."
+
+	var user models.User
+	err := database.DB.First(&user, "email = ?", input.Email).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		utils.AuthLogger.Printf("Password reset failed (user not found): %s", input.Email)
+		return utils.SendError(c, fiber.StatusBadRequest, invalidMessage)
+	}
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Database query error")
+	}
+
+	clearResetToken := map[string]interface{}{
+		"password_reset_token":      nil,
+		"password_reset_expires_at": nil,
+		"password_reset_attempts":   0,
+	}
+
+	if !user.PasswordResetToken.Valid || !user.PasswordResetExpiresAt.Valid {
+		utils.AuthLogger.Printf("Password reset failed (no active OTP): %s", user.UUID)
+		return utils.SendError(c, fiber.StatusBadRequest, invalidMessage)
+	}
+
+	if time.Now().After(user.PasswordResetExpiresAt.Time) {
+		database.DB.Model(&user).Updates(clearResetToken)
+		utils.AuthLogger.Printf("Password reset failed (expired): %s", user.UUID)
+		return utils.SendError(c, fiber.StatusBadRequest, "Kode OTP telah kedaluwarsa. Silakan minta kode baru.")
+	}
+
+	allowed, err := utils.ConsumeOTPAttempt(user.ID, "password_reset_token", user.PasswordResetToken.String, "password_reset_attempts")
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to record password reset attempt for %s: %v", user.UUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Database query error")
+	}
+	if !allowed {
+		if err := database.DB.Model(&user).Where("password_reset_token = ?", user.PasswordResetToken.String).Updates(clearResetToken).Error; err != nil {
+			utils.ErrorLogger.Printf("Failed to cancel password reset OTP for %s: %v", user.UUID, err)
+		}
+		utils.AuthLogger.Printf("Password reset OTP locked out after %d failed attempts: %s", constants.OTPMaxAttempts, user.UUID)
+		return utils.SendError(c, fiber.StatusTooManyRequests, "Terlalu banyak percobaan. Silakan minta kode baru.")
+	}
+
+	if !utils.CheckOTP(input.OTP, user.PasswordResetToken.String) {
+		utils.AuthLogger.Printf("Password reset failed (wrong OTP): %s", user.UUID)
+		return utils.SendError(c, fiber.StatusBadRequest, invalidMessage)
+	}
+
+	hashedPassword, err := utils.HashPassword(input.NewPassword)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to process new password")
+	}
+
+	updates := map[string]interface{}{"password": hashedPassword}
+	for column, value := range clearResetToken {
+		updates[column] = value
+	}
+	if err := database.DB.Model(&user).Updates(updates).Error; err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to update password")
+	}
+
+	if err := utils.RevokeAllUserTokens(user.ID, utils.SessionRevokedPasswordChange); err != nil {
+		utils.ErrorLogger.Printf("Failed to revoke tokens after password reset for %s: %v", user.UUID, err)
+	}
+
+	// Pemilik akun sudah membuktikan akses ke email-nya, jadi kunci login ikut dibuka.
+	if err := utils.ResetLoginFailures(models.LoginLockoutScopeAccount, user.UUID); err != nil {
+		utils.ErrorLogger.Printf("Failed to reset login failures after password reset for %s: %v", user.UUID, err)
+	}
+
+	utils.AuthLogger.Printf("Password reset successful: %s", user.UUID)
+	return utils.SendSuccess(c, fiber.StatusOK, "Password berhasil diubah. Silakan login dengan password baru Anda.", nil)
+}
+
 func Login(c *fiber.Ctx) error {
 	input := c.Locals("request_body").(*dto.LoginRequest)
+	clientIP := c.IP()
+
+	if lockedUntil, locked := utils.GetLoginLockout(models.LoginLockoutScopeIP, clientIP); locked {
+		utils.AuthLogger.Printf("Login blocked (IP locked until %s): %s", lockedUntil.Format(time.RFC3339), clientIP)
+		return utils.SendError(c, fiber.StatusTooManyRequests, loginLockedMessage(lockedUntil))
+	}
 
 	var user models.User
 	err := database.DB.Preload("Roles").Preload("Profile").First(&user, "email = ?", input.Email).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
+		recordIPLoginFailure(clientIP)
 		utils.AuthLogger.Printf("Login failed (user not found): %s", input.Email)
 		return utils.SendError(c, fiber.StatusUnauthorized, "Invalid credentials")
 	}
@@ -238,19 +449,56 @@ func Login(c *fiber.Ctx) error {
 		return utils.SendError(c, fiber.StatusInternalServerError, "Database query error")
 	}
 
+	if lockedUntil, locked := utils.GetLoginLockout(models.LoginLockoutScopeAccount, user.UUID); locked {
+		utils.AuthLogger.Printf("Login blocked (account locked until %s): %s", lockedUntil.Format(time.RFC3339), input.Email)
+		return utils.SendError(c, fiber.StatusLocked, loginLockedMessage(lockedUntil))
+	}
+
 	match, err := utils.CheckPasswordHash(input.Password, user.Password)
 	if err != nil || !match {
+		recordIPLoginFailure(clientIP)
+		lockedUntil, locked, lockErr := utils.RecordLoginFailure(models.LoginLockoutScopeAccount, user.UUID, constants.LoginMaxFailedAttempts)
+		if lockErr != nil {
+			utils.ErrorLogger.Printf("Failed to record login failure for %s: %v", user.UUID, lockErr)
+		}
+		if locked {
+			utils.AuthLogger.Printf("Account locked until %s after repeated failed logins: %s", lockedUntil.Format(time.RFC3339), input.Email)
+			go func(email string) {
+				if err := utils.SendAccountLockedEmail(email, lockedUntil); err != nil {
+					utils.ErrorLogger.Printf("Failed to send account locked email to %s: %v", email, err)
+				}
+			}(user.Email)
+			return utils.SendError(c, fiber.StatusLocked, loginLockedMessage(lockedUntil))
+		}
+
 		utils.AuthLogger.Printf("Login failed (invalid credentials): %s", input.Email)
 		return utils.SendError(c, fiber.StatusUnauthorized, "Invalid credentials")
 	}
 
-	token, err := utils.GenerateJWT(user)
+	if err := utils.ResetLoginFailures(models.LoginLockoutScopeAccount, user.UUID); err != nil {
+		utils.ErrorLogger.Printf("Failed to reset login failures for %s: %v", user.UUID, err)
+	}
+
+	upgradePasswordHash(&user, input.Password)
+
+	needsTwoFactor, err := requiresTwoFactor(user)
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to check 2FA requirement for %s: %v", user.UUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to verify account permissions")
+	}
+	if needsTwoFactor {
+		return sendTwoFactorChallenge(c, user, input.DeviceName)
+	}
+
+	token, refreshToken, err := utils.CreateSession(user, input.DeviceName, c.Get(fiber.HeaderUserAgent), c.IP())
 	if err != nil {
+		utils.ErrorLogger.Printf("Failed to create session for %s: %v", user.Email, err)
 		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to generate JWT token")
 	}
 
 	go utils.AddUserToFrequentLoginFilter(user)
 	responseData := dto.UserResponseJson(user, token)
+	responseData.RefreshToken = refreshToken
 
 	utils.AuthLogger.Printf("User login successful: %s (UUID: %s)", responseData.Email, responseData.ID)
 	return utils.SendSuccess(c, fiber.StatusOK, "Login successful", responseData)
@@ -286,22 +534,60 @@ func Logout(c *fiber.Ctx) error {
 		return utils.SendSuccess(c, fiber.StatusOK, "Token already expired", nil)
 	}
 
-	// invalidToken := models.InvalidToken{
-	// 	Token:     tokenString,
-	// 	ExpiresAt: expiresAt,
-	// }
-
-	// if err := database.DB.Create(&invalidToken).Error; err != nil {
-	// 	if strings.Contains(err.Error(), "Duplicate entry") {
-	// 		// Token is already blocklisted
-	// 	} else {
-	// 		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to invalidate token")
-	// 	}
-	// }
+	jti, _ := claims["jti"].(string)
+	if err := utils.RevokeToken(jti, expiresAt); err != nil {
+		utils.ErrorLogger.Printf("Failed to invalidate token %s: %v", jti, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to invalidate token")
+	}
 
-	// remainingDuration := time.Until(expiresAt)
-	// utils.AddToBlocklistCache(tokenString, remainingDuration)
+	if sessionUUID, ok := c.Locals("session_id").(string); ok {
+		if err := utils.RevokeSession(sessionUUID, utils.SessionRevokedLogout); err != nil {
+			utils.ErrorLogger.Printf("Failed to revoke session %s: %v", sessionUUID, err)
+			return utils.SendError(c, fiber.StatusInternalServerError, "Failed to revoke session")
+		}
+	}
 
 	utils.AuthLogger.Printf("Logout successful: %s", userUUID)
 	return utils.SendSuccess(c, fiber.StatusOK, "Logout successful", nil)
 }
+
+func RefreshToken(c *fiber.Ctx) error {
+	input := c.Locals("request_body").(*dto.RefreshTokenRequest)
+
+	user, token, refreshToken, err := utils.RotateRefreshToken(input.RefreshToken, c.Get(fiber.HeaderUserAgent), c.IP())
+	if err != nil {
+		switch {
+		case errors.Is(err, utils.ErrRefreshTokenReused):
+			return utils.SendError(c, fiber.StatusUnauthorized, "Refresh token has already been used. Please log in again.")
+		case errors.Is(err, utils.ErrRefreshTokenExpired):
+			utils.AuthLogger.Printf("Refresh failed (expired) from IP %s", c.IP())
+			return utils.SendError(c, fiber.StatusUnauthorized, "Refresh token has expired. Please log in again.")
+		case errors.Is(err, utils.ErrRefreshTokenInvalid):
+			utils.AuthLogger.Printf("Refresh failed (invalid token) from IP %s", c.IP())
+			return utils.SendError(c, fiber.StatusUnauthorized, "Invalid refresh token")
+		default:
+			utils.ErrorLogger.Printf("Failed to rotate refresh token: %v", err)
+			return utils.SendError(c, fiber.StatusInternalServerError, "Failed to refresh token")
+		}
+	}
+
+	utils.AuthLogger.Printf("Token refreshed: %s", user.UUID)
+	return utils.SendSuccess(c, fiber.StatusOK, "Token refreshed successfully", dto.TokenResponse{
+		AccessToken:  token,
+		RefreshToken: refreshToken,
+	})
+}
+
+// GetJWKS mempublikasikan public key penandatangan JWT (format JWKS, RFC 7517)
+// agar layanan partner dapat memverifikasi access token secara lokal.
+func GetJWKS(c *fiber.Ctx) error {
+	keys, err := utils.GetJWKS()
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to build JWKS: %v", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to load signing keys")
+	}
+
+	// Format JWKS standar, tidak dibungkus response envelope agar bisa dibaca library JWT.
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(fiber.Map{"keys": keys})
+}
@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"ipincamp/srikandi-sehat/config"
+	"ipincamp/srikandi-sehat/database"
+	"ipincamp/srikandi-sehat/src/constants"
+	"ipincamp/srikandi-sehat/src/dto"
+	"ipincamp/srikandi-sehat/src/models"
+	"ipincamp/srikandi-sehat/src/utils"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// --- Helper functions for Consent ---
+
+// findLatestConsent mengambil persetujuan terbaru user untuk versi naskah yang berlaku.
+func findLatestConsent(userID uint) (*models.Consent, error) {
+	var consent models.Consent
+	err := database.DB.
+		Where("user_id = ? AND version = ?", userID, constants.CurrentConsentVersion).
+		Order("created_at DESC").
+		First(&consent).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &consent, nil
+}
+
+// findPendingGuardianConsent mencari persetujuan wali yang masih menunggu konfirmasi berdasarkan token.
+func findPendingGuardianConsent(token string) (*models.Consent, error) {
+	var consent models.Consent
+	err := database.DB.Preload("User").
+		Where("token_hash = ?", utils.HashGuardianConsentToken(token)).
+		First(&consent).Error
+	if err != nil {
+		return nil, err
+	}
+	if dto.ConsentStatus(consent) != "pending" || consent.Version != constants.CurrentConsentVersion {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &consent, nil
+}
+
+// guardianConsentPage membuat halaman HTML sederhana untuk wali yang membuka tautan dari email.
+func guardianConsentPage(title, body string) string {
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html lang="id">
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>%s - Srikandi Sehat</title>
+</head>
+<body style="font-family: Arial, sans-serif; line-height: 1.6; max-width: 560px; margin: 40px auto; padding: 0 16px;">
+	<h2>%s</h2>
+	%s
+	<p>Tim Srikandi Sehat</p>
+</body>
+</html>`, html.EscapeString(title), html.EscapeString(title), body)
+}
+
+// --- Handlers ---
+
+// GetMyConsent mengembalikan status persetujuan user untuk versi naskah yang berlaku.
+func GetMyConsent(c *fiber.Ctx) error {
+	userUUID := c.Locals("user_id").(string)
+
+	var user models.User
+	if err := database.DB.Preload("Profile").First(&user, "uuid = ?", userUUID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	ageLimit := utils.GuardianConsentAge()
+	response := dto.ConsentStatusResponse{
+		RequiredVersion:  constants.CurrentConsentVersion,
+		GuardianAgeLimit: ageLimit,
+		RequiresGuardian: user.Profile.DateOfBirth != nil && calculateAge(user.Profile.DateOfBirth) < ageLimit,
+	}
+
+	consent, err := findLatestConsent(user.ID)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Database error")
+	}
+	if consent != nil {
+		consentResponse := dto.ConsentResponseJson(*consent)
+		response.Consent = &consentResponse
+		response.ConsentGranted = consentResponse.Status == "granted"
+	}
+
+	return utils.SendSuccess(c, fiber.StatusOK, "Consent status fetched successfully", response)
+}
+
+// GiveConsent mencatat persetujuan user. User dewasa langsung tercatat; untuk user di bawah
+// batas umur, tautan konfirmasi dikirim ke email wali dan persetujuan menunggu konfirmasi wali.
+func GiveConsent(c *fiber.Ctx) error {
+	userUUID := c.Locals("user_id").(string)
+	input := c.Locals("request_body").(*dto.GiveConsentRequest)
+
+	var user models.User
+	if err := database.DB.Preload("Profile").First(&user, "uuid = ?", userUUID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	if user.Profile.DateOfBirth == nil {
+		return utils.SendError(c, fiber.StatusUnprocessableEntity, "Please complete your date of birth in your profile before giving consent.")
+	}
+
+	latest, err := findLatestConsent(user.ID)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Database error")
+	}
+	if latest != nil && dto.ConsentStatus(*latest) == "granted" {
+		return utils.SendError(c, fiber.StatusConflict, "Consent has already been given for the current version")
+	}
+
+	now := time.Now()
+	if calculateAge(user.Profile.DateOfBirth) >= utils.GuardianConsentAge() {
+		consent := models.Consent{
+			UserID:    user.ID,
+			Version:   constants.CurrentConsentVersion,
+			Type:      constants.SelfConsent,
+			GrantedAt: sql.NullTime{Time: now, Valid: true},
+			GrantedIP: sql.NullString{String: c.IP(), Valid: true},
+		}
+		if err := database.DB.Create(&consent).Error; err != nil {
+			utils.ErrorLogger.Printf("Failed to record consent for %s: %v", userUUID, err)
+			return utils.SendError(c, fiber.StatusInternalServerError, "Failed to record consent")
+		}
+
+		utils.InfoLogger.Printf("Consent v%s granted by user: %s", consent.Version, userUUID)
+		return utils.SendSuccess(c, fiber.StatusCreated, "Consent recorded successfully", dto.ConsentResponseJson(consent))
+	}
+
+	// --- User di bawah umur: butuh konfirmasi wali ---
+	if input.GuardianName == nil || input.GuardianEmail == nil {
+		return utils.SendError(c, fiber.StatusUnprocessableEntity, "Guardian name and email are required for users under the age limit.")
+	}
+	guardianEmail := strings.TrimSpace(*input.GuardianEmail)
+	if strings.EqualFold(guardianEmail, user.Email) {
+		return utils.SendError(c, fiber.StatusUnprocessableEntity, "Guardian email must be different from your own email.")
+	}
+
+	token, tokenHash, err := utils.NewGuardianConsentToken()
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to generate guardian consent token for %s: %v", userUUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to process consent")
+	}
+	expiresAt := now.Add(constants.GuardianConsentLinkExpiryHours * time.Hour)
+
+	consent := models.Consent{
+		UserID:         user.ID,
+		Version:        constants.CurrentConsentVersion,
+		Type:           constants.GuardianConsent,
+		GuardianName:   sql.NullString{String: strings.TrimSpace(*input.GuardianName), Valid: true},
+		GuardianEmail:  sql.NullString{String: guardianEmail, Valid: true},
+		TokenHash:      sql.NullString{String: tokenHash, Valid: true},
+		TokenExpiresAt: sql.NullTime{Time: expiresAt, Valid: true},
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		// Tautan lama yang belum dikonfirmasi tidak berlaku lagi.
+		if err := tx.Where("user_id = ? AND granted_at IS NULL AND revoked_at IS NULL", user.ID).
+			Delete(&models.Consent{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&consent).Error
+	})
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to create guardian consent request for %s: %v", userUUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to process consent")
+	}
+
+	consentLink := fmt.Sprintf("%s/api/consents/guardian/%s", config.Get("APP_BASE_URL"), token)
+	if err := utils.SendGuardianConsentEmail(guardianEmail, consent.GuardianName.String, user.Name, consentLink, expiresAt); err != nil {
+		utils.ErrorLogger.Printf("Failed to send guardian consent email for %s: %v", userUUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to send guardian consent email")
+	}
+
+	utils.InfoLogger.Printf("Guardian consent requested for user: %s", userUUID)
+	return utils.SendSuccess(c, fiber.StatusAccepted, "A confirmation link has been sent to your guardian's email. Access will be granted once your guardian confirms.", dto.ConsentResponseJson(consent))
+}
+
+// WithdrawMyConsent mencabut persetujuan yang berlaku. Akses ke data kesehatan diblokir lagi
+// sampai persetujuan baru diberikan.
+func WithdrawMyConsent(c *fiber.Ctx) error {
+	userUUID := c.Locals("user_id").(string)
+
+	var user models.User
+	if err := database.DB.Select("id").First(&user, "uuid = ?", userUUID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	result := database.DB.Model(&models.Consent{}).
+		Where("user_id = ? AND granted_at IS NOT NULL AND revoked_at IS NULL", user.ID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to withdraw consent")
+	}
+	if result.RowsAffected == 0 {
+		return utils.SendError(c, fiber.StatusConflict, "No active consent to withdraw")
+	}
+
+	utils.InfoLogger.Printf("Consent withdrawn by user: %s", userUUID)
+	return utils.SendSuccess(c, fiber.StatusOK, "Consent withdrawn successfully", nil)
+}
+
+// ShowGuardianConsent menampilkan halaman konfirmasi untuk wali. Persetujuan baru tercatat
+// setelah wali menekan tombol (POST), sehingga pemindai tautan email tidak ikut menyetujui.
+func ShowGuardianConsent(c *fiber.Ctx) error {
+	params := c.Locals("request_params").(*dto.GuardianConsentParam)
+	c.Type("html", "utf-8")
+
+	consent, err := findPendingGuardianConsent(params.Token)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).SendString(guardianConsentPage(
+			"Tautan Tidak Berlaku",
+			"<p>Tautan persetujuan ini tidak valid, sudah kedaluwarsa, atau sudah digunakan.</p>",
+		))
+	}
+
+	body := fmt.Sprintf(`
+	<p>Yth. %s,</p>
+	<p><strong>%s</strong> meminta persetujuan Anda sebagai orang tua/wali untuk mencatat data kesehatan menstruasinya
+	(tanggal siklus, gejala, dan catatan terkait) di aplikasi Srikandi Sehat, sesuai naskah persetujuan versi %s.</p>
+	<p>Data digunakan untuk pemantauan kesehatan reproduksi dan dapat dihapus kapan saja melalui aplikasi.</p>
+	<form method="POST">
+		<button type="submit" style="padding: 10px 20px; background-color: #D63384; color: #fff; border: none; border-radius: 4px; cursor: pointer;">
+			Saya Setuju
+		</button>
+	</form>
+	<p style="color: #888;">Jika Anda tidak setuju, cukup tutup halaman ini.</p>`,
+		html.EscapeString(consent.GuardianName.String),
+		html.EscapeString(consent.User.Name),
+		html.EscapeString(consent.Version),
+	)
+
+	return c.SendString(guardianConsentPage("Persetujuan Orang Tua/Wali", body))
+}
+
+// ConfirmGuardianConsent mencatat persetujuan wali dari tautan email.
+func ConfirmGuardianConsent(c *fiber.Ctx) error {
+	params := c.Locals("request_params").(*dto.GuardianConsentParam)
+	c.Type("html", "utf-8")
+
+	consent, err := findPendingGuardianConsent(params.Token)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).SendString(guardianConsentPage(
+			"Tautan Tidak Berlaku",
+			"<p>Tautan persetujuan ini tidak valid, sudah kedaluwarsa, atau sudah digunakan.</p>",
+		))
+	}
+
+	updates := map[string]interface{}{
+		"granted_at":       time.Now(),
+		"granted_ip":       c.IP(),
+		"token_hash":       nil,
+		"token_expires_at": nil,
+	}
+	if err := database.DB.Model(consent).Updates(updates).Error; err != nil {
+		utils.ErrorLogger.Printf("Failed to record guardian consent %s: %v", consent.UUID, err)
+		return c.Status(fiber.StatusInternalServerError).SendString(guardianConsentPage(
+			"Terjadi Kesalahan",
+			"<p>Persetujuan gagal disimpan. Silakan coba lagi beberapa saat lagi.</p>",
+		))
+	}
+
+	utils.InfoLogger.Printf("Guardian consent v%s granted for user: %s", consent.Version, consent.User.UUID)
+	return c.SendString(guardianConsentPage(
+		"Terima Kasih",
+		"<p>Persetujuan Anda telah tercatat. Anak Anda sekarang dapat menggunakan fitur pencatatan kesehatan di aplikasi Srikandi Sehat.</p>",
+	))
+}
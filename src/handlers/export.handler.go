@@ -0,0 +1,309 @@
+package handlers
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"ipincamp/srikandi-sehat/config"
+	"ipincamp/srikandi-sehat/database"
+	"ipincamp/srikandi-sehat/src/dto"
+	"ipincamp/srikandi-sehat/src/models"
+	"ipincamp/srikandi-sehat/src/models/menstrual"
+	"ipincamp/srikandi-sehat/src/utils"
+	"os"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const personalExportExpiration = 30 * time.Minute
+
+// --- Helper functions for Personal Data Export ---
+
+// buildPersonalDataExport mengumpulkan seluruh data milik user dan menuliskannya sebagai arsip ZIP berisi file JSON.
+// Arsip ditulis ke file sementara (.part) lalu di-rename agar unduhan tidak pernah membaca arsip setengah jadi.
+func buildPersonalDataExport(userID uint, path string) error {
+	var user models.User
+	if err := database.DB.
+		Preload("Profile.Village.Classification").
+		Preload("Profile.Village.District.Regency.Province").
+		First(&user, userID).Error; err != nil {
+		return err
+	}
+
+	account := dto.ExportAccount{
+		ID:           user.UUID,
+		Name:         user.Name,
+		Email:        user.Email,
+		RegisteredAt: user.CreatedAt,
+		Profile:      dto.UserResponseJson(user).Profile,
+	}
+	if user.EmailVerifiedAt.Valid {
+		account.EmailVerifiedAt = &user.EmailVerifiedAt.Time
+	}
+	if village := user.Profile.Village; village.ID > 0 {
+		account.Region = &dto.ExportRegionPath{
+			Province:       dto.ExportRegion{Code: village.District.Regency.Province.Code, Name: village.District.Regency.Province.Name},
+			Regency:        dto.ExportRegion{Code: village.District.Regency.Code, Name: village.District.Regency.Name},
+			District:       dto.ExportRegion{Code: village.District.Code, Name: village.District.Name},
+			Village:        dto.ExportRegion{Code: village.Code, Name: village.Name},
+			Classification: village.Classification.Name,
+		}
+	}
+
+	// Termasuk siklus yang sudah dihapus (soft delete) beserta alasannya.
+	var cycles []menstrual.MenstrualCycle
+	if err := database.DB.Unscoped().Where("user_id = ?", userID).Order("start_date ASC").Find(&cycles).Error; err != nil {
+		return err
+	}
+	exportCycles := make([]dto.ExportCycle, 0, len(cycles))
+	for _, cycle := range cycles {
+		entry := dto.ExportCycle{
+			ID:        cycle.ID,
+			StartDate: cycle.StartDate,
+			CreatedAt: cycle.CreatedAt,
+		}
+		if cycle.EndDate.Valid {
+			entry.EndDate = &cycle.EndDate.Time
+		}
+		if cycle.PeriodLength.Valid {
+			entry.PeriodLength = &cycle.PeriodLength.Int16
+		}
+		if cycle.CycleLength.Valid {
+			entry.CycleLength = &cycle.CycleLength.Int16
+		}
+		if cycle.IsPeriodNormal.Valid {
+			entry.IsPeriodNormal = &cycle.IsPeriodNormal.Bool
+		}
+		if cycle.IsCycleNormal.Valid {
+			entry.IsCycleNormal = &cycle.IsCycleNormal.Bool
+		}
+		if cycle.DeletedAt.Valid {
+			entry.DeletedAt = &cycle.DeletedAt.Time
+			if cycle.DeletionReason.Valid {
+				entry.DeletionReason = &cycle.DeletionReason.String
+			}
+		}
+		exportCycles = append(exportCycles, entry)
+	}
+
+	var symptomLogs []menstrual.SymptomLog
+	if err := database.DB.
+		Preload("Details.Symptom").
+		Preload("Details.SymptomOption").
+		Where("user_id = ?", userID).
+		Order("logged_at ASC").
+		Find(&symptomLogs).Error; err != nil {
+		return err
+	}
+	exportLogs := make([]dto.ExportSymptomLog, 0, len(symptomLogs))
+	for _, log := range symptomLogs {
+		entry := dto.ExportSymptomLog{
+			ID:        log.ID,
+			LoggedAt:  log.LoggedAt,
+			Note:      log.Note,
+			Details:   make([]dto.ExportSymptomDetail, 0, len(log.Details)),
+			CreatedAt: log.CreatedAt,
+		}
+		if log.MenstrualCycleID.Valid {
+			entry.MenstrualCycleID = &log.MenstrualCycleID.Int64
+		}
+		for _, detail := range log.Details {
+			exportDetail := dto.ExportSymptomDetail{
+				Symptom:  detail.Symptom.Name,
+				Category: detail.Symptom.Category,
+			}
+			if detail.SymptomOptionID.Valid {
+				exportDetail.OptionName = &detail.SymptomOption.Name
+				exportDetail.OptionValue = &detail.SymptomOption.Value
+			}
+			if detail.Severity.Valid {
+				exportDetail.Severity = &detail.Severity.Int16
+			}
+			entry.Details = append(entry.Details, exportDetail)
+		}
+		exportLogs = append(exportLogs, entry)
+	}
+
+	var flowLogs []menstrual.FlowLog
+	if err := database.DB.Where("user_id = ?", userID).Order("date ASC").Find(&flowLogs).Error; err != nil {
+		return err
+	}
+	exportFlowLogs := make([]dto.ExportFlowLog, 0, len(flowLogs))
+	for _, flowLog := range flowLogs {
+		entry := dto.ExportFlowLog{
+			Date:             flowLog.Date.Format("2006-01-02"),
+			Intensity:        string(flowLog.Intensity),
+			IsIntermenstrual: flowLog.IsIntermenstrual,
+			Note:             flowLog.Note,
+			CreatedAt:        flowLog.CreatedAt,
+		}
+		if flowLog.MenstrualCycleID.Valid {
+			entry.MenstrualCycleID = &flowLog.MenstrualCycleID.Int64
+		}
+		exportFlowLogs = append(exportFlowLogs, entry)
+	}
+
+	var notifications []models.Notification
+	if err := database.DB.Where("user_id = ?", userID).Order("created_at ASC").Find(&notifications).Error; err != nil {
+		return err
+	}
+	exportNotifications := make([]dto.ExportNotification, 0, len(notifications))
+	for _, notification := range notifications {
+		exportNotifications = append(exportNotifications, dto.ExportNotification{
+			Title:     notification.Title,
+			Body:      notification.Body,
+			IsRead:    notification.IsRead,
+			CreatedAt: notification.CreatedAt,
+		})
+	}
+
+	var consents []models.Consent
+	if err := database.DB.Where("user_id = ?", userID).Order("created_at ASC").Find(&consents).Error; err != nil {
+		return err
+	}
+	exportConsents := make([]dto.ExportConsent, 0, len(consents))
+	for _, consent := range consents {
+		entry := dto.ExportConsent{
+			Version:     consent.Version,
+			Type:        string(consent.Type),
+			RequestedAt: consent.CreatedAt,
+		}
+		if consent.GuardianName.Valid {
+			entry.GuardianName = &consent.GuardianName.String
+		}
+		if consent.GuardianEmail.Valid {
+			entry.GuardianEmail = &consent.GuardianEmail.String
+		}
+		if consent.GrantedAt.Valid {
+			entry.GrantedAt = &consent.GrantedAt.Time
+		}
+		if consent.RevokedAt.Valid {
+			entry.RevokedAt = &consent.RevokedAt.Time
+		}
+		exportConsents = append(exportConsents, entry)
+	}
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"account.json", account},
+		{"menstrual_cycles.json", exportCycles},
+		{"symptom_logs.json", exportLogs},
+		{"flow_logs.json", exportFlowLogs},
+		{"notifications.json", exportNotifications},
+		{"consents.json", exportConsents},
+	}
+
+	partPath := path + ".part"
+	file, err := os.Create(partPath)
+	if err != nil {
+		return err
+	}
+
+	archive := zip.NewWriter(file)
+	for _, f := range files {
+		writer, err := archive.Create(f.name)
+		if err != nil {
+			file.Close()
+			os.Remove(partPath)
+			return err
+		}
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(f.data); err != nil {
+			file.Close()
+			os.Remove(partPath)
+			return err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		file.Close()
+		os.Remove(partPath)
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(partPath)
+		return err
+	}
+
+	return os.Rename(partPath, path)
+}
+
+// --- Handlers ---
+
+// RequestPersonalDataExport memulai pembuatan arsip data pribadi di background
+// dan mengembalikan tautan unduhan sekali pakai.
+func RequestPersonalDataExport(c *fiber.Ctx) error {
+	userUUID := c.Locals("user_id").(string)
+
+	var user models.User
+	if err := database.DB.Select("id").First(&user, "uuid = ?", userUUID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	token := uuid.New().String()
+	expiresAt := time.Now().Add(personalExportExpiration)
+	path := utils.PersonalExportPath(userUUID, token)
+
+	utils.StoreReportToken(utils.PersonalExportTokenPrefix+token, personalExportExpiration)
+
+	go func(userID uint) {
+		if err := buildPersonalDataExport(userID, path); err != nil {
+			utils.ErrorLogger.Printf("Failed to build personal data export for user %d: %v", userID, err)
+		}
+	}(user.ID)
+
+	// Hapus arsip yang tidak pernah diunduh setelah tautan kedaluwarsa.
+	time.AfterFunc(personalExportExpiration, func() {
+		os.Remove(path)
+		os.Remove(path + ".part")
+	})
+
+	response := dto.PersonalDataExportResponse{
+		DownloadURL: fmt.Sprintf("%s/api/exports/download/%s", config.Get("APP_BASE_URL"), token),
+		ExpiresAt:   expiresAt,
+	}
+
+	utils.InfoLogger.Printf("Personal data export requested: %s", userUUID)
+	return utils.SendSuccess(c, fiber.StatusAccepted, "Your data export is being prepared. The one-time download link expires in 30 minutes.", response)
+}
+
+// DownloadPersonalDataExport mengirim arsip ekspor jika sudah selesai dibuat dan token masih valid.
+func DownloadPersonalDataExport(c *fiber.Ctx) error {
+	token := c.Params("token")
+	if _, err := uuid.Parse(token); err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "Link is invalid, has expired, or has already been used.")
+	}
+
+	path, found := utils.FindPersonalExport(token)
+	if !found {
+		return utils.SendError(c, fiber.StatusNotFound, "Link is invalid, has expired, or has already been used.")
+	}
+	if _, err := os.Stat(path); err != nil {
+		// Arsip masih dibuat: token belum dikonsumsi agar user bisa mencoba lagi.
+		if _, partErr := os.Stat(path + ".part"); partErr == nil {
+			return utils.SendSuccess(c, fiber.StatusAccepted, "Your data export is still being prepared. Please try again shortly.", nil)
+		}
+		return utils.SendError(c, fiber.StatusNotFound, "Link is invalid, has expired, or has already been used.")
+	}
+
+	if !utils.UseReportToken(utils.PersonalExportTokenPrefix + token) {
+		return utils.SendError(c, fiber.StatusNotFound, "Link is invalid, has expired, or has already been used.")
+	}
+
+	data, err := os.ReadFile(path)
+	os.Remove(path)
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to read personal data export %s: %v", token, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to read data export")
+	}
+
+	filename := fmt.Sprintf("srikandi-sehat_data-export_%s.zip", time.Now().Format("2006-01-02_15-04-05"))
+	c.Set("Content-Type", "application/zip")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	return c.Send(data)
+}
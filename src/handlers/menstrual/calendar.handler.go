@@ -0,0 +1,167 @@
+package menstrual
+
+import (
+	"ipincamp/srikandi-sehat/database"
+	"ipincamp/srikandi-sehat/src/constants"
+	"ipincamp/srikandi-sehat/src/dto"
+	"ipincamp/srikandi-sehat/src/models"
+	menstrual "ipincamp/srikandi-sehat/src/models/menstrual"
+	"ipincamp/srikandi-sehat/src/utils"
+	"math"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const calendarDateFormat = "2006-01-02"
+
+// calendarDay mengembalikan awal hari (00:00) dari t pada zona waktu loc.
+func calendarDay(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+}
+
+// markCalendarRange menandai hari-hari dalam rentang [from, to] dengan state tertentu,
+// tanpa menimpa state yang prioritasnya lebih tinggi.
+func markCalendarRange(days map[string]*dto.CalendarDayResponse, from, to time.Time, state constants.CalendarDayState) {
+	priority := map[string]int{
+		string(constants.CalendarDayNone):            0,
+		string(constants.CalendarDayFertile):         1,
+		string(constants.CalendarDayOvulation):       2,
+		string(constants.CalendarDayPredictedPeriod): 3,
+		string(constants.CalendarDayPeriod):          4,
+	}
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		entry, found := days[day.Format(calendarDateFormat)]
+		if found && priority[string(state)] > priority[entry.State] {
+			entry.State = string(state)
+		}
+	}
+}
+
+// GetCalendar mengembalikan status setiap hari dalam satu bulan: haid tercatat, prediksi haid,
+// ovulasi, masa subur, serta log gejala, semuanya dihitung pada zona waktu user.
+func GetCalendar(c *fiber.Ctx) error {
+	userUUID := c.Locals("user_id").(string)
+	queries := c.Locals("request_queries").(*dto.CalendarQuery)
+
+	loc := time.Local
+	if queries.Timezone != "" {
+		location, err := time.LoadLocation(queries.Timezone)
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid timezone")
+		}
+		loc = location
+	}
+
+	month, err := time.ParseInLocation("2006-01", queries.Month, loc)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid month format")
+	}
+	monthStart := month
+	monthEnd := monthStart.AddDate(0, 1, 0)
+
+	var user models.User
+	if err := database.DB.First(&user, "uuid = ?", userUUID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	days := make(map[string]*dto.CalendarDayResponse)
+	orderedDays := make([]*dto.CalendarDayResponse, 0, 31)
+	for day := monthStart; day.Before(monthEnd); day = day.AddDate(0, 0, 1) {
+		entry := &dto.CalendarDayResponse{
+			Date:          day.Format(calendarDateFormat),
+			State:         string(constants.CalendarDayNone),
+			SymptomLogIDs: []uint{},
+		}
+		days[entry.Date] = entry
+		orderedDays = append(orderedDays, entry)
+	}
+
+	// 1. Haid yang tercatat. Siklus aktif ditandai sampai hari ini.
+	var cycles []menstrual.MenstrualCycle
+	err = database.DB.
+		Where("user_id = ? AND start_date < ? AND (end_date IS NULL OR end_date >= ?)", user.ID, monthEnd, monthStart).
+		Order("start_date asc").
+		Find(&cycles).Error
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to retrieve cycle data")
+	}
+
+	today := calendarDay(time.Now(), loc)
+	for _, cycle := range cycles {
+		from := calendarDay(cycle.StartDate, loc)
+		to := today
+		if cycle.EndDate.Valid {
+			to = calendarDay(cycle.EndDate.Time, loc)
+		}
+		markCalendarRange(days, from, to, constants.CalendarDayPeriod)
+
+		cycleID := cycle.ID
+		for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+			if entry, found := days[day.Format(calendarDateFormat)]; found {
+				entry.CycleID = &cycleID
+			}
+		}
+	}
+
+	// 2. Prediksi haid, ovulasi, dan masa subur, diproyeksikan beberapa siklus ke depan.
+	prediction, ok, err := utils.PredictNextPeriodForUserIn(database.DB, user.ID, loc)
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to predict next period for user %s: %v", userUUID, err)
+	}
+	if ok {
+		cycleDays := int(math.Round(prediction.CycleLength))
+		for i := 0; i < constants.CalendarMaxPredictedCycles; i++ {
+			offset := i * cycleDays
+			predictedStart := prediction.PredictedStart.AddDate(0, 0, offset)
+			fertileStart := prediction.FertileWindowStart.AddDate(0, 0, offset)
+			if !fertileStart.Before(monthEnd) {
+				break
+			}
+
+			predictedEnd := predictedStart.AddDate(0, 0, prediction.PeriodLength-1)
+			ovulation := prediction.OvulationDate.AddDate(0, 0, offset)
+
+			// Prediksi untuk hari yang sudah lewat tidak ditampilkan sebagai haid prediksi.
+			if predictedEnd.Before(today) {
+				continue
+			}
+			if predictedStart.Before(today) {
+				predictedStart = today
+			}
+
+			markCalendarRange(days, predictedStart, predictedEnd, constants.CalendarDayPredictedPeriod)
+			markCalendarRange(days, fertileStart, prediction.FertileWindowEnd.AddDate(0, 0, offset), constants.CalendarDayFertile)
+			markCalendarRange(days, ovulation, ovulation, constants.CalendarDayOvulation)
+		}
+	}
+
+	// 3. Log gejala.
+	var symptomLogs []menstrual.SymptomLog
+	err = database.DB.Select("id", "logged_at").
+		Where("user_id = ? AND logged_at >= ? AND logged_at < ?", user.ID, monthStart, monthEnd).
+		Order("logged_at asc").
+		Find(&symptomLogs).Error
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to retrieve symptom data")
+	}
+	for _, log := range symptomLogs {
+		if entry, found := days[calendarDay(log.LoggedAt, loc).Format(calendarDateFormat)]; found {
+			entry.HasSymptoms = true
+			entry.SymptomLogIDs = append(entry.SymptomLogIDs, log.ID)
+		}
+	}
+
+	response := dto.CalendarResponse{
+		Month:    queries.Month,
+		Timezone: loc.String(),
+		Days:     make([]dto.CalendarDayResponse, 0, len(orderedDays)),
+	}
+	for _, entry := range orderedDays {
+		response.Days = append(response.Days, *entry)
+	}
+
+	return utils.SendSuccess(c, fiber.StatusOK, "Calendar fetched successfully", response)
+}
@@ -11,6 +11,7 @@ import (
 	menstrual "ipincamp/srikandi-sehat/src/models/menstrual"
 	"ipincamp/srikandi-sehat/src/utils"
 	"log"
+	"sort"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -74,7 +75,12 @@ func RecordCycle(c *fiber.Ctx) error {
 			return utils.SendError(c, fiber.StatusInternalServerError, "Failed to record new cycle")
 		}
 
-		updatePreviousCycleLength(tx, user.ID, startDate)
+		if err := updatePreviousCycleLength(tx, user.ID, startDate); err != nil {
+			return utils.SendError(c, fiber.StatusInternalServerError, "Failed to calculate previous cycle length")
+		}
+		if err := relinkFlowLogs(tx, user.ID, startDate, nil); err != nil {
+			return utils.SendError(c, fiber.StatusInternalServerError, "Failed to relink flow logs")
+		}
 		isStartRequest = true
 	}
 
@@ -94,7 +100,12 @@ func RecordCycle(c *fiber.Ctx) error {
 			return utils.SendError(c, fiber.StatusBadRequest, errorMessage)
 		}
 
-		updateCurrentCyclePeriod(tx, &activeCycle, endDate)
+		if err := updateCurrentCyclePeriod(tx, &activeCycle, endDate); err != nil {
+			return utils.SendError(c, fiber.StatusInternalServerError, "Failed to end cycle")
+		}
+		if err := relinkFlowLogs(tx, user.ID, activeCycle.StartDate, nil); err != nil {
+			return utils.SendError(c, fiber.StatusInternalServerError, "Failed to relink flow logs")
+		}
 		isStartRequest = false
 	}
 
@@ -108,6 +119,129 @@ func RecordCycle(c *fiber.Ctx) error {
 	return utils.SendSuccess(c, fiber.StatusOK, "Cycle ended successfully", nil)
 }
 
+// ImportCycles mencatat beberapa periode lama sekaligus (misalnya saat onboarding).
+// Seluruh data divalidasi sebagai satu set lalu disimpan dalam satu transaksi.
+func ImportCycles(c *fiber.Ctx) error {
+	userUUID := c.Locals("user_id").(string)
+	input := c.Locals("request_body").(*dto.ImportCyclesRequest)
+
+	var user models.User
+	if err := database.DB.Preload("Profile").First(&user, "uuid = ?", userUUID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	if user.Profile.ID == 0 {
+		return utils.SendError(c, fiber.StatusForbidden, "Please complete your profile before recording a cycle. Essential data is missing.")
+	}
+
+	type importedPeriod struct {
+		start time.Time
+		end   time.Time
+	}
+
+	now := time.Now()
+	periods := make([]importedPeriod, 0, len(input.Cycles))
+	for i, entry := range input.Cycles {
+		startDate, err := time.Parse(time.RFC3339, entry.StartDate)
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, fmt.Sprintf("Cycle #%d: invalid StartDate format", i+1))
+		}
+		endDate, err := time.Parse(time.RFC3339, entry.EndDate)
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, fmt.Sprintf("Cycle #%d: invalid EndDate format", i+1))
+		}
+		if endDate.Before(startDate) {
+			return utils.SendError(c, fiber.StatusBadRequest, fmt.Sprintf("Cycle #%d: finish date cannot be before the start date.", i+1))
+		}
+		if endDate.After(now) {
+			return utils.SendError(c, fiber.StatusBadRequest, fmt.Sprintf("Cycle #%d: dates cannot be in the future.", i+1))
+		}
+		periods = append(periods, importedPeriod{start: startDate, end: endDate})
+	}
+
+	sort.Slice(periods, func(i, j int) bool { return periods[i].start.Before(periods[j].start) })
+	for i := 1; i < len(periods); i++ {
+		if !periods[i].start.After(periods[i-1].end) {
+			formattedDate := periods[i].start.Format("2 January 2006")
+			return utils.SendError(c, fiber.StatusConflict, fmt.Sprintf("Imported cycles overlap each other around %s.", formattedDate))
+		}
+	}
+
+	tx := database.DB.Begin()
+	if tx.Error != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to start transaction")
+	}
+	defer tx.Rollback()
+
+	// Periode yang diimpor juga tidak boleh bertabrakan dengan siklus yang sudah tercatat (termasuk siklus aktif).
+	for _, period := range periods {
+		var existing menstrual.MenstrualCycle
+		err := tx.Where("user_id = ? AND start_date <= ? AND (end_date IS NULL OR end_date >= ?)", user.ID, period.end, period.start).
+			First(&existing).Error
+		if err == nil {
+			formattedDate := existing.StartDate.Format("2 January 2006")
+			errorMessage := fmt.Sprintf("Imported cycle starting %s overlaps with the recorded cycle starting %s.", period.start.Format("2 January 2006"), formattedDate)
+			return utils.SendError(c, fiber.StatusConflict, errorMessage)
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.SendError(c, fiber.StatusInternalServerError, "Failed to check existing cycles")
+		}
+	}
+
+	cycles := make([]menstrual.MenstrualCycle, 0, len(periods))
+	for _, period := range periods {
+		cycle := menstrual.MenstrualCycle{UserID: user.ID, StartDate: period.start}
+		if err := tx.Create(&cycle).Error; err != nil {
+			utils.ErrorLogger.Printf("Failed to import cycle for user %s: %v", userUUID, err)
+			return utils.SendError(c, fiber.StatusInternalServerError, "Failed to import cycles")
+		}
+		if err := updateCurrentCyclePeriod(tx, &cycle, period.end); err != nil {
+			utils.ErrorLogger.Printf("Failed to calculate imported period for user %s: %v", userUUID, err)
+			return utils.SendError(c, fiber.StatusInternalServerError, "Failed to import cycles")
+		}
+		cycles = append(cycles, cycle)
+	}
+
+	// Panjang siklus dihitung setelah semua periode tersimpan, termasuk siklus lama
+	// yang kini diikuti periode impor dan periode impor terakhir yang diikuti siklus yang sudah ada.
+	for _, cycle := range cycles {
+		if err := updatePreviousCycleLength(tx, user.ID, cycle.StartDate); err != nil {
+			return utils.SendError(c, fiber.StatusInternalServerError, "Failed to calculate cycle lengths")
+		}
+		if err := refreshCycleLength(tx, user.ID, cycle); err != nil {
+			return utils.SendError(c, fiber.StatusInternalServerError, "Failed to calculate cycle lengths")
+		}
+	}
+
+	lastEnd := periods[len(periods)-1].end
+	if err := relinkSymptomLogs(tx, user.ID, periods[0].start, &lastEnd); err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to relink symptom logs")
+	}
+	if err := relinkFlowLogs(tx, user.ID, periods[0].start, &lastEnd); err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to relink flow logs")
+	}
+
+	ids := make([]uint, 0, len(cycles))
+	for _, cycle := range cycles {
+		ids = append(ids, cycle.ID)
+	}
+	var imported []menstrual.MenstrualCycle
+	if err := tx.Where("id IN ?", ids).Order("start_date asc").Find(&imported).Error; err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to retrieve imported cycles")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to commit transaction")
+	}
+
+	responseData := make([]dto.CycleResponse, 0, len(imported))
+	for _, cycle := range imported {
+		responseData = append(responseData, cycleResponseJson(cycle))
+	}
+
+	return utils.SendSuccess(c, fiber.StatusCreated, fmt.Sprintf("%d cycles imported successfully", len(responseData)), responseData)
+}
+
 func GetCycleHistory(c *fiber.Ctx) error {
 	userUUID := c.Locals("user_id").(string)
 	queries := c.Locals("request_queries").(*dto.PaginationQuery)
@@ -143,27 +277,7 @@ func GetCycleHistory(c *fiber.Ctx) error {
 
 	var responseData []dto.CycleResponse
 	for _, cycle := range cycles {
-		dto := dto.CycleResponse{
-			ID:        cycle.ID,
-			StartDate: cycle.StartDate,
-		}
-
-		if cycle.EndDate.Valid {
-			dto.EndDate = &cycle.EndDate.Time
-		}
-		if cycle.PeriodLength.Valid {
-			dto.PeriodLength = &cycle.PeriodLength.Int16
-		}
-		if cycle.CycleLength.Valid {
-			dto.CycleLength = &cycle.CycleLength.Int16
-		}
-		if cycle.IsPeriodNormal.Valid {
-			dto.IsPeriodNormal = &cycle.IsPeriodNormal.Bool
-		}
-		if cycle.IsCycleNormal.Valid {
-			dto.IsCycleNormal = &cycle.IsCycleNormal.Bool
-		}
-		responseData = append(responseData, dto)
+		responseData = append(responseData, cycleResponseJson(cycle))
 	}
 
 	paginatedResponse := dto.PaginatedResponse[dto.CycleResponse]{
@@ -221,6 +335,9 @@ func GetCycleByID(c *fiber.Ctx) error {
 			if detail.SymptomOptionID.Valid && detail.SymptomOption.Name != "" {
 				symptomDetail.SelectedOption = &detail.SymptomOption.Name
 			}
+			if detail.Severity.Valid {
+				symptomDetail.Severity = &detail.Severity.Int16
+			}
 			details = append(details, symptomDetail)
 		}
 
@@ -235,10 +352,16 @@ func GetCycleByID(c *fiber.Ctx) error {
 		symptomGroups = append(symptomGroups, group)
 	}
 
+	var flowLogs []menstrual.FlowLog
+	if err := database.DB.Where("menstrual_cycle_id = ?", cycle.ID).Order("date asc").Find(&flowLogs).Error; err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to retrieve flow data")
+	}
+
 	response := dto.CycleDetailResponse{
-		ID:        cycle.ID,
-		StartDate: cycle.StartDate,
-		Symptoms:  symptomGroups,
+		ID:          cycle.ID,
+		StartDate:   cycle.StartDate,
+		FlowSummary: flowSummary(flowLogs),
+		Symptoms:    symptomGroups,
 	}
 
 	if cycle.EndDate.Valid {
@@ -293,6 +416,10 @@ func GetCycleStatus(c *fiber.Ctx) error {
 			response.IsCycleNormal = &isCycleNormal
 		}
 
+		if prediction, ok, err := utils.PredictNextPeriodForUser(database.DB, user.ID); err == nil && ok {
+			response.Prediction = cyclePredictionResponseJson(prediction)
+		}
+
 		return utils.SendSuccess(c, fiber.StatusOK, "Cycle status fetched.", response)
 	}
 
@@ -329,26 +456,24 @@ func GetCycleStatus(c *fiber.Ctx) error {
 		}
 
 		// Predict next period
-		var totalCycleLength int
-		var validCyclesForAvg int
-		for _, cycle := range completedCycles {
-			if cycle.CycleLength.Valid {
-				totalCycleLength += int(cycle.CycleLength.Int16)
-				validCyclesForAvg++
-			}
+		prediction, ok, err := utils.PredictNextPeriodForUser(database.DB, user.ID)
+		if err != nil {
+			utils.ErrorLogger.Printf("Failed to predict next period for user %s: %v", userUUID, err)
 		}
 
-		if validCyclesForAvg > 0 {
-			averageCycleLength := totalCycleLength / validCyclesForAvg
-			lastStartDate := completedCycles[0].StartDate
-			predictedDate := lastStartDate.AddDate(0, 0, averageCycleLength)
+		if ok {
+			response.Prediction = cyclePredictionResponseJson(prediction)
+			predictedDate := prediction.PredictedStart
 			daysUntil := int(time.Until(predictedDate).Hours() / 24)
 
 			if daysUntil >= 0 {
 				predictedDateStr := predictedDate.Format("2006-01-02")
 				response.DaysUntilNextPeriod = &daysUntil
 				response.PredictedPeriodDate = &predictedDateStr
-				response.Message = fmt.Sprintf("Periode menstruasi Anda berikutnya diprediksi dalam %d hari.", daysUntil)
+				response.Message = fmt.Sprintf(
+					"Periode menstruasi Anda berikutnya diprediksi dalam %d hari (antara %s dan %s).",
+					daysUntil, prediction.EarliestStart.Format("2 January"), prediction.LatestStart.Format("2 January"),
+				)
 			} else {
 				response.Message = "Tanggal prediksi menstruasi Anda telah lewat. Silakan catat siklus baru jika sudah dimulai."
 			}
@@ -362,6 +487,143 @@ func GetCycleStatus(c *fiber.Ctx) error {
 	return utils.SendError(c, fiber.StatusInternalServerError, "Failed to retrieve cycle data")
 }
 
+// UpdateCycleByID mengoreksi tanggal mulai/selesai sebuah siklus, lalu menghitung ulang
+// lama haid dan panjang siklus ini serta siklus sebelumnya, dan menautkan ulang log gejala.
+func UpdateCycleByID(c *fiber.Ctx) error {
+	userUUID := c.Locals("user_id").(string)
+	params := c.Locals("request_params").(*dto.CycleParam)
+	input := c.Locals("request_body").(*dto.UpdateCycleRequest)
+
+	if input.StartDate == "" && input.EndDate == "" {
+		return utils.SendError(c, fiber.StatusBadRequest, "StartDate or EndDate must be provided")
+	}
+
+	tx := database.DB.Begin()
+	if tx.Error != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to start transaction")
+	}
+	defer tx.Rollback()
+
+	var user models.User
+	if err := tx.First(&user, "uuid = ?", userUUID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	var cycle menstrual.MenstrualCycle
+	if err := tx.Where("id = ? AND user_id = ?", params.ID, user.ID).First(&cycle).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.SendError(c, fiber.StatusNotFound, "Cycle not found")
+		}
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to retrieve cycle data")
+	}
+
+	oldStartDate := cycle.StartDate
+	oldEndDate := cycle.EndDate
+
+	newStartDate := cycle.StartDate
+	if input.StartDate != "" {
+		parsed, err := time.Parse(time.RFC3339, input.StartDate)
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid StartDate format")
+		}
+		newStartDate = parsed
+	}
+
+	newEndDate := cycle.EndDate
+	if input.EndDate != "" {
+		parsed, err := time.Parse(time.RFC3339, input.EndDate)
+		if err != nil {
+			return utils.SendError(c, fiber.StatusBadRequest, "Invalid EndDate format")
+		}
+		newEndDate = sql.NullTime{Time: parsed, Valid: true}
+	}
+
+	if newEndDate.Valid && newEndDate.Time.Before(newStartDate) {
+		return utils.SendError(c, fiber.StatusBadRequest, "Finish date cannot be before the start date of the cycle.")
+	}
+	now := time.Now()
+	if newStartDate.After(now) || (newEndDate.Valid && newEndDate.Time.After(now)) {
+		return utils.SendError(c, fiber.StatusBadRequest, "Cycle dates cannot be in the future.")
+	}
+
+	// Urutan siklus tidak boleh berubah: siklus ini harus tetap berada di antara siklus sebelum dan sesudahnya.
+	var previousCycle menstrual.MenstrualCycle
+	errPrev := tx.Where("user_id = ? AND id <> ? AND start_date < ?", user.ID, cycle.ID, oldStartDate).
+		Order("start_date desc").
+		First(&previousCycle).Error
+	if errPrev == nil && previousCycle.EndDate.Valid && !newStartDate.After(previousCycle.EndDate.Time) {
+		formattedDate := previousCycle.EndDate.Time.Format("2 January 2006 15:04:05")
+		errorMessage := fmt.Sprintf("Start date cannot be before or equal to the end date of the previous cycle (%s).", formattedDate)
+		return utils.SendError(c, fiber.StatusConflict, errorMessage)
+	}
+
+	var nextCycle menstrual.MenstrualCycle
+	errNext := tx.Where("user_id = ? AND id <> ? AND start_date > ?", user.ID, cycle.ID, oldStartDate).
+		Order("start_date asc").
+		First(&nextCycle).Error
+	if errNext == nil {
+		if !newEndDate.Valid || !newEndDate.Time.Before(nextCycle.StartDate) {
+			formattedDate := nextCycle.StartDate.Format("2 January 2006 15:04:05")
+			errorMessage := fmt.Sprintf("Cycle dates must end before the start date of the next cycle (%s).", formattedDate)
+			return utils.SendError(c, fiber.StatusConflict, errorMessage)
+		}
+	}
+
+	if !newStartDate.Equal(oldStartDate) {
+		if err := tx.Model(&cycle).Update("start_date", newStartDate).Error; err != nil {
+			return utils.SendError(c, fiber.StatusInternalServerError, "Failed to update cycle")
+		}
+		cycle.StartDate = newStartDate
+
+		// Panjang siklus sebelumnya bergantung pada tanggal mulai siklus ini,
+		// dan panjang siklus ini bergantung pada tanggal mulai siklus berikutnya.
+		if err := updatePreviousCycleLength(tx, user.ID, newStartDate); err != nil {
+			return utils.SendError(c, fiber.StatusInternalServerError, "Failed to recalculate cycle lengths")
+		}
+		if errNext == nil {
+			if err := updatePreviousCycleLength(tx, user.ID, nextCycle.StartDate); err != nil {
+				return utils.SendError(c, fiber.StatusInternalServerError, "Failed to recalculate cycle lengths")
+			}
+		}
+	}
+
+	if newEndDate.Valid {
+		if err := updateCurrentCyclePeriod(tx, &cycle, newEndDate.Time); err != nil {
+			return utils.SendError(c, fiber.StatusInternalServerError, "Failed to recalculate period length")
+		}
+	}
+
+	// Log gejala yang berada di rentang lama maupun baru ditautkan ulang ke siklus yang sesuai.
+	relinkFrom := oldStartDate
+	if newStartDate.Before(relinkFrom) {
+		relinkFrom = newStartDate
+	}
+	var relinkTo *time.Time
+	if oldEndDate.Valid && newEndDate.Valid {
+		to := newEndDate.Time
+		if oldEndDate.Time.After(to) {
+			to = oldEndDate.Time
+		}
+		relinkTo = &to
+	}
+	if err := relinkSymptomLogs(tx, user.ID, relinkFrom, relinkTo); err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to relink symptom logs")
+	}
+	if err := relinkFlowLogs(tx, user.ID, relinkFrom, relinkTo); err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to relink flow logs")
+	}
+
+	if err := tx.First(&cycle, cycle.ID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to retrieve cycle data")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to commit transaction")
+	}
+
+	return utils.SendSuccess(c, fiber.StatusOK, "Cycle updated successfully", cycleResponseJson(cycle))
+}
+
 func DeleteCycleByID(c *fiber.Ctx) error {
 	userUUID := c.Locals("user_id").(string)
 	params := c.Locals("request_params").(*dto.CycleParam)
@@ -399,11 +661,220 @@ func DeleteCycleByID(c *fiber.Ctx) error {
 		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to delete cycle")
 	}
 
+	// Siklus sebelumnya kini bersambung ke siklus setelah siklus yang dihapus (atau menjadi siklus terakhir).
+	var previousCycle menstrual.MenstrualCycle
+	if err := tx.Where("user_id = ? AND start_date < ?", user.ID, cycle.StartDate).Order("start_date desc").First(&previousCycle).Error; err == nil {
+		if err := refreshCycleLength(tx, user.ID, previousCycle); err != nil {
+			return utils.SendError(c, fiber.StatusInternalServerError, "Failed to recalculate previous cycle")
+		}
+	}
+
+	if err := relinkSymptomLogs(tx, user.ID, cycle.StartDate, cycleEndBound(cycle)); err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to relink symptom logs")
+	}
+	if err := relinkFlowLogs(tx, user.ID, cycle.StartDate, cycleEndBound(cycle)); err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to relink flow logs")
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to commit transaction")
 	}
 
-	return utils.SendSuccess(c, fiber.StatusOK, "Cycle deleted successfully", nil)
+	response := dto.DeleteCycleResponse{
+		RestorableUntil: time.Now().AddDate(0, 0, constants.CycleRestoreRetentionDays),
+	}
+	return utils.SendSuccess(c, fiber.StatusOK, "Cycle deleted successfully", response)
+}
+
+// RestoreCycleByID memulihkan siklus yang dihapus selama masih dalam masa retensi,
+// lalu menghitung ulang panjang siklus tetangga dan menautkan kembali log gejalanya.
+func RestoreCycleByID(c *fiber.Ctx) error {
+	userUUID := c.Locals("user_id").(string)
+	params := c.Locals("request_params").(*dto.CycleParam)
+
+	tx := database.DB.Begin()
+	if tx.Error != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to start transaction")
+	}
+	defer tx.Rollback()
+
+	var user models.User
+	if err := tx.First(&user, "uuid = ?", userUUID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	var cycle menstrual.MenstrualCycle
+	err := tx.Unscoped().
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", params.ID, user.ID).
+		First(&cycle).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.SendError(c, fiber.StatusNotFound, "Deleted cycle not found")
+		}
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to retrieve cycle data")
+	}
+
+	if time.Since(cycle.DeletedAt.Time) > constants.CycleRestoreRetentionDays*24*time.Hour {
+		errorMessage := fmt.Sprintf("Deleted cycles can only be restored within %d days.", constants.CycleRestoreRetentionDays)
+		return utils.SendError(c, fiber.StatusGone, errorMessage)
+	}
+
+	// Siklus yang dipulihkan tidak boleh bertabrakan dengan siklus yang dicatat setelah penghapusan.
+	overlapQuery := tx.Model(&menstrual.MenstrualCycle{}).
+		Where("user_id = ? AND (end_date IS NULL OR end_date >= ?)", user.ID, cycle.StartDate)
+	if cycle.EndDate.Valid {
+		overlapQuery = overlapQuery.Where("start_date <= ?", cycle.EndDate.Time)
+	}
+	var overlapping int64
+	if err := overlapQuery.Count(&overlapping).Error; err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to check overlapping cycles")
+	}
+	if !cycle.EndDate.Valid {
+		var laterCycles int64
+		tx.Model(&menstrual.MenstrualCycle{}).Where("user_id = ? AND start_date > ?", user.ID, cycle.StartDate).Count(&laterCycles)
+		overlapping += laterCycles
+	}
+	if overlapping > 0 {
+		return utils.SendError(c, fiber.StatusConflict, "Cannot restore this cycle because it overlaps with another recorded cycle.")
+	}
+
+	if err := tx.Unscoped().Model(&cycle).Updates(map[string]interface{}{
+		"deleted_at":      nil,
+		"deletion_reason": nil,
+	}).Error; err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to restore cycle")
+	}
+
+	if err := updatePreviousCycleLength(tx, user.ID, cycle.StartDate); err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to recalculate previous cycle")
+	}
+	if err := refreshCycleLength(tx, user.ID, cycle); err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to recalculate cycle length")
+	}
+
+	if err := relinkSymptomLogs(tx, user.ID, cycle.StartDate, cycleEndBound(cycle)); err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to relink symptom logs")
+	}
+	if err := relinkFlowLogs(tx, user.ID, cycle.StartDate, cycleEndBound(cycle)); err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to relink flow logs")
+	}
+
+	if err := tx.First(&cycle, cycle.ID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to retrieve cycle data")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to commit transaction")
+	}
+
+	return utils.SendSuccess(c, fiber.StatusOK, "Cycle restored successfully", cycleResponseJson(cycle))
+}
+
+func cyclePredictionResponseJson(prediction utils.CyclePrediction) *dto.CyclePredictionResponse {
+	return &dto.CyclePredictionResponse{
+		PredictedPeriodDate: prediction.PredictedStart.Format("2006-01-02"),
+		EarliestPeriodDate:  prediction.EarliestStart.Format("2006-01-02"),
+		LatestPeriodDate:    prediction.LatestStart.Format("2006-01-02"),
+		OvulationDate:       prediction.OvulationDate.Format("2006-01-02"),
+		FertileWindowStart:  prediction.FertileWindowStart.Format("2006-01-02"),
+		FertileWindowEnd:    prediction.FertileWindowEnd.Format("2006-01-02"),
+		AverageCycleLength:  prediction.CycleLength,
+		CycleLengthStdDev:   prediction.StdDev,
+		SampleSize:          prediction.SampleSize,
+		ExcludedOutliers:    prediction.RejectedSamples,
+	}
+}
+
+func cycleResponseJson(cycle menstrual.MenstrualCycle) dto.CycleResponse {
+	response := dto.CycleResponse{
+		ID:        cycle.ID,
+		StartDate: cycle.StartDate,
+	}
+
+	if cycle.EndDate.Valid {
+		response.EndDate = &cycle.EndDate.Time
+	}
+	if cycle.PeriodLength.Valid {
+		response.PeriodLength = &cycle.PeriodLength.Int16
+	}
+	if cycle.CycleLength.Valid {
+		response.CycleLength = &cycle.CycleLength.Int16
+	}
+	if cycle.IsPeriodNormal.Valid {
+		response.IsPeriodNormal = &cycle.IsPeriodNormal.Bool
+	}
+	if cycle.IsCycleNormal.Valid {
+		response.IsCycleNormal = &cycle.IsCycleNormal.Bool
+	}
+	return response
+}
+
+// findCycleForDate mencari siklus (yang belum dihapus) yang mencakup waktu tertentu.
+func findCycleForDate(tx *gorm.DB, userID uint, at time.Time) (menstrual.MenstrualCycle, error) {
+	var cycle menstrual.MenstrualCycle
+	err := tx.Where("user_id = ? AND start_date <= ? AND (end_date IS NULL OR end_date >= ?)", userID, at, at).
+		Order("start_date desc").
+		First(&cycle).Error
+	return cycle, err
+}
+
+// relinkSymptomLogs menautkan ulang log gejala user dalam rentang [from, to] ke siklus yang mencakupnya,
+// atau melepasnya jika tidak ada siklus yang cocok. to bernilai nil berarti tanpa batas akhir.
+func relinkSymptomLogs(tx *gorm.DB, userID uint, from time.Time, to *time.Time) error {
+	query := tx.Where("user_id = ? AND logged_at >= ?", userID, from)
+	if to != nil {
+		query = query.Where("logged_at <= ?", *to)
+	}
+
+	var logs []menstrual.SymptomLog
+	if err := query.Find(&logs).Error; err != nil {
+		return err
+	}
+
+	for _, symptomLog := range logs {
+		cycleID := sql.NullInt64{}
+		if cycle, err := findCycleForDate(tx, userID, symptomLog.LoggedAt); err == nil {
+			cycleID = sql.NullInt64{Int64: int64(cycle.ID), Valid: true}
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if cycleID == symptomLog.MenstrualCycleID {
+			continue
+		}
+		if err := tx.Model(&symptomLog).Update("menstrual_cycle_id", cycleID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cycleEndBound mengembalikan batas akhir rentang siklus untuk relinkSymptomLogs/relinkFlowLogs (nil untuk siklus aktif).
+func cycleEndBound(cycle menstrual.MenstrualCycle) *time.Time {
+	if !cycle.EndDate.Valid {
+		return nil
+	}
+	return &cycle.EndDate.Time
+}
+
+// refreshCycleLength menghitung ulang panjang siklus berdasarkan tanggal mulai siklus berikutnya.
+// Jika tidak ada siklus berikutnya, panjang siklus dikosongkan.
+func refreshCycleLength(tx *gorm.DB, userID uint, cycle menstrual.MenstrualCycle) error {
+	var nextCycle menstrual.MenstrualCycle
+	err := tx.Where("user_id = ? AND start_date > ?", userID, cycle.StartDate).
+		Order("start_date asc").
+		First(&nextCycle).Error
+	if err == nil {
+		return updatePreviousCycleLength(tx, userID, nextCycle.StartDate)
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	return tx.Model(&cycle).Updates(map[string]interface{}{
+		"cycle_length":    nil,
+		"is_cycle_normal": nil,
+	}).Error
 }
 
 func findActiveCycle(tx *gorm.DB, userID uint) (menstrual.MenstrualCycle, error) {
@@ -414,35 +885,77 @@ func findActiveCycle(tx *gorm.DB, userID uint) (menstrual.MenstrualCycle, error)
 	return activeCycle, err
 }
 
-func updatePreviousCycleLength(tx *gorm.DB, userID uint, newStartDate time.Time) {
+// updatePreviousCycleLength menghitung panjang siklus sebelum newStartDate. Tidak adanya siklus sebelumnya bukan error.
+func updatePreviousCycleLength(tx *gorm.DB, userID uint, newStartDate time.Time) error {
 	var previousCycle menstrual.MenstrualCycle
 	err := tx.Where("user_id = ? AND start_date < ?", userID, newStartDate).
 		Order("start_date desc").
 		First(&previousCycle).Error
-
-	if err == nil {
-		cycleLength := int16(newStartDate.Sub(previousCycle.StartDate).Hours() / 24)
-		isNormal := cycleLength >= constants.CycleLengthMinNormalDays && cycleLength <= constants.CycleLengthMaxNormalDays
-
-		tx.Model(&previousCycle).Updates(map[string]interface{}{
-			"cycle_length":    cycleLength,
-			"is_cycle_normal": isNormal,
-		})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
 	}
-}
 
-func updateCurrentCyclePeriod(tx *gorm.DB, currentCycle *menstrual.MenstrualCycle, endDate time.Time) {
-	loc := time.Local
+	cycleLength, isNormal := utils.CalculateCycleLength(previousCycle.StartDate, newStartDate)
 
-	startDay := time.Date(currentCycle.StartDate.Year(), currentCycle.StartDate.Month(), currentCycle.StartDate.Day(), 0, 0, 0, 0, loc)
-	endDay := time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, loc)
+	return tx.Model(&previousCycle).Updates(map[string]interface{}{
+		"cycle_length":    cycleLength,
+		"is_cycle_normal": isNormal,
+	}).Error
+}
 
-	periodLength := int16(endDay.Sub(startDay).Hours()/24) + 1
-	isNormal := periodLength >= constants.CyclePeriodMinNormalDays && periodLength <= constants.CyclePeriodMaxNormalDays
+func updateCurrentCyclePeriod(tx *gorm.DB, currentCycle *menstrual.MenstrualCycle, endDate time.Time) error {
+	periodLength, isNormal := utils.CalculatePeriodLength(currentCycle.StartDate, endDate)
 
-	tx.Model(currentCycle).Updates(map[string]interface{}{
+	return tx.Model(currentCycle).Updates(map[string]interface{}{
 		"end_date":         endDate,
 		"period_length":    periodLength,
 		"is_period_normal": isNormal,
-	})
+	}).Error
+}
+
+// GetCycleAnalytics mengembalikan statistik keteraturan siklus user dalam beberapa bulan terakhir
+// beserta penanda pola yang relevan secara klinis.
+func GetCycleAnalytics(c *fiber.Ctx) error {
+	userUUID := c.Locals("user_id").(string)
+	queries := c.Locals("request_queries").(*dto.CycleAnalyticsQuery)
+
+	months := queries.Months
+	if months == 0 {
+		months = constants.CycleAnalyticsDefaultMonths
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, "uuid = ?", userUUID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	now := time.Now()
+	from := now.AddDate(0, -months, 0)
+
+	var cycles []menstrual.MenstrualCycle
+	err := database.DB.Where("user_id = ? AND start_date >= ?", user.ID, from).
+		Order("start_date asc").
+		Find(&cycles).Error
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to fetch cycles for analytics of user %s: %v", userUUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to retrieve cycle data")
+	}
+
+	// Amenore dihitung dari haid terakhir secara keseluruhan, bukan hanya di dalam jendela analitik.
+	var lastPeriodStart *time.Time
+	if len(cycles) > 0 {
+		lastPeriodStart = &cycles[len(cycles)-1].StartDate
+	} else {
+		var latestCycle menstrual.MenstrualCycle
+		if err := database.DB.Where("user_id = ?", user.ID).Order("start_date desc").First(&latestCycle).Error; err == nil {
+			lastPeriodStart = &latestCycle.StartDate
+		}
+	}
+
+	response := utils.AnalyzeCycles(cycles, lastPeriodStart, months, from, now)
+
+	return utils.SendSuccess(c, fiber.StatusOK, "Cycle analytics fetched successfully", response)
 }
@@ -0,0 +1,246 @@
+package menstrual
+
+import (
+	"database/sql"
+	"errors"
+	"ipincamp/srikandi-sehat/database"
+	"ipincamp/srikandi-sehat/src/constants"
+	"ipincamp/srikandi-sehat/src/dto"
+	"ipincamp/srikandi-sehat/src/models"
+	menstrual "ipincamp/srikandi-sehat/src/models/menstrual"
+	"ipincamp/srikandi-sehat/src/utils"
+	"slices"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const flowDateFormat = "2006-01-02"
+
+// --- Helper functions for Flow Logs ---
+
+// findCycleForDay mencari siklus (yang belum dihapus) yang mencakup tanggal tertentu (00:00 waktu lokal).
+func findCycleForDay(tx *gorm.DB, userID uint, day time.Time) (menstrual.MenstrualCycle, error) {
+	var cycle menstrual.MenstrualCycle
+	err := tx.Where("user_id = ? AND start_date < ? AND (end_date IS NULL OR end_date >= ?)", userID, day.AddDate(0, 0, 1), day).
+		Order("start_date desc").
+		First(&cycle).Error
+	return cycle, err
+}
+
+// relinkFlowLogs menautkan ulang catatan aliran dalam rentang [from, to] ke siklus yang mencakupnya.
+// Catatan tanpa siklus ditandai sebagai perdarahan intermenstrual. to bernilai nil berarti tanpa batas akhir.
+func relinkFlowLogs(tx *gorm.DB, userID uint, from time.Time, to *time.Time) error {
+	fromDay := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.Local)
+	query := tx.Where("user_id = ? AND date >= ?", userID, fromDay)
+	if to != nil {
+		query = query.Where("date <= ?", *to)
+	}
+
+	var logs []menstrual.FlowLog
+	if err := query.Find(&logs).Error; err != nil {
+		return err
+	}
+
+	for _, flowLog := range logs {
+		cycleID := sql.NullInt64{}
+		if cycle, err := findCycleForDay(tx, userID, flowLog.Date); err == nil {
+			cycleID = sql.NullInt64{Int64: int64(cycle.ID), Valid: true}
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		if cycleID == flowLog.MenstrualCycleID && flowLog.IsIntermenstrual == !cycleID.Valid {
+			continue
+		}
+		if err := tx.Model(&flowLog).Updates(map[string]interface{}{
+			"menstrual_cycle_id": cycleID,
+			"is_intermenstrual":  !cycleID.Valid,
+		}).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func flowLogResponseJson(flowLog menstrual.FlowLog) dto.FlowLogResponse {
+	response := dto.FlowLogResponse{
+		ID:               flowLog.ID,
+		Date:             flowLog.Date.Format(flowDateFormat),
+		Intensity:        string(flowLog.Intensity),
+		IsIntermenstrual: flowLog.IsIntermenstrual,
+	}
+	if flowLog.MenstrualCycleID.Valid {
+		response.CycleID = &flowLog.MenstrualCycleID.Int64
+	}
+	if flowLog.Note != "" {
+		response.Note = &flowLog.Note
+	}
+	return response
+}
+
+// flowSummary merangkum catatan aliran satu siklus. Mengembalikan nil jika belum ada catatan.
+func flowSummary(logs []menstrual.FlowLog) *dto.FlowSummaryResponse {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	summary := &dto.FlowSummaryResponse{
+		LoggedDays:      len(logs),
+		DaysByIntensity: make(map[string]int),
+	}
+	peak := -1
+	for _, flowLog := range logs {
+		summary.DaysByIntensity[string(flowLog.Intensity)]++
+
+		switch flowLog.Intensity {
+		case constants.FlowHeavy:
+			summary.HeavyDays++
+		case constants.FlowClots:
+			summary.HeavyDays++
+			summary.ClotDays++
+		}
+
+		if level := slices.Index(constants.FlowIntensityLevels, flowLog.Intensity); level > peak {
+			peak = level
+			summary.PeakIntensity = string(flowLog.Intensity)
+		}
+	}
+
+	summary.PossibleHeavyBleeding = summary.HeavyDays >= constants.FlowHeavyDaysThreshold ||
+		summary.ClotDays >= constants.FlowClotDaysThreshold
+	return summary
+}
+
+// --- Handlers ---
+
+// LogFlow mencatat (atau memperbarui) intensitas aliran untuk satu hari.
+func LogFlow(c *fiber.Ctx) error {
+	userUUID := c.Locals("user_id").(string)
+	input := c.Locals("request_body").(*dto.FlowLogRequest)
+
+	day, err := time.ParseInLocation(flowDateFormat, input.Date, time.Local)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusBadRequest, "Invalid date format")
+	}
+	if day.After(time.Now()) {
+		return utils.SendError(c, fiber.StatusBadRequest, "Date cannot be in the future")
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, "uuid = ?", userUUID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	tx := database.DB.Begin()
+	if tx.Error != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to start transaction")
+	}
+	defer tx.Rollback()
+
+	var flowLog menstrual.FlowLog
+	err = tx.Where("user_id = ? AND date = ?", user.ID, day).First(&flowLog).Error
+	isNew := errors.Is(err, gorm.ErrRecordNotFound)
+	if err != nil && !isNew {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to retrieve flow log")
+	}
+
+	flowLog.UserID = user.ID
+	flowLog.Date = day
+	flowLog.Intensity = constants.FlowIntensity(input.Intensity)
+	flowLog.Note = input.Note
+	flowLog.MenstrualCycleID = sql.NullInt64{}
+	if cycle, err := findCycleForDay(tx, user.ID, day); err == nil {
+		flowLog.MenstrualCycleID = sql.NullInt64{Int64: int64(cycle.ID), Valid: true}
+	}
+	flowLog.IsIntermenstrual = !flowLog.MenstrualCycleID.Valid
+
+	if err := tx.Save(&flowLog).Error; err != nil {
+		utils.ErrorLogger.Printf("Failed to save flow log for user %s: %v", userUUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to save flow log")
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to commit transaction")
+	}
+
+	message := "Flow logged successfully"
+	if flowLog.IsIntermenstrual {
+		message = "Flow logged as intermenstrual bleeding (outside a recorded period)"
+	}
+	status := fiber.StatusOK
+	if isNew {
+		status = fiber.StatusCreated
+	}
+	return utils.SendSuccess(c, status, message, flowLogResponseJson(flowLog))
+}
+
+// GetFlowLogs mengembalikan catatan aliran user, terbaru lebih dulu.
+func GetFlowLogs(c *fiber.Ctx) error {
+	userUUID := c.Locals("user_id").(string)
+	queries := c.Locals("request_queries").(*dto.FlowLogQuery)
+
+	page := queries.Page
+	if page <= 0 {
+		page = 1
+	}
+	limit := queries.Limit
+	if limit <= 0 {
+		limit = 31
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, "uuid = ?", userUUID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	baseQuery := database.DB.Model(&menstrual.FlowLog{}).Where("user_id = ?", user.ID)
+	if queries.StartDate != "" {
+		baseQuery = baseQuery.Where("date >= ?", queries.StartDate)
+	}
+	if queries.EndDate != "" {
+		baseQuery = baseQuery.Where("date <= ?", queries.EndDate)
+	}
+	if queries.IntermenstrualOnly {
+		baseQuery = baseQuery.Where("is_intermenstrual = ?", true)
+	}
+
+	pagination, paginateScope := utils.GeneratePagination(page, limit, baseQuery, &menstrual.FlowLog{})
+
+	var logs []menstrual.FlowLog
+	if err := baseQuery.Scopes(paginateScope).Order("date desc").Find(&logs).Error; err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to retrieve flow logs")
+	}
+
+	results := make([]dto.FlowLogResponse, 0, len(logs))
+	for _, flowLog := range logs {
+		results = append(results, flowLogResponseJson(flowLog))
+	}
+
+	return utils.SendSuccess(c, fiber.StatusOK, "Flow logs fetched successfully", dto.PaginatedResponse[dto.FlowLogResponse]{
+		Data:     results,
+		Metadata: pagination,
+	})
+}
+
+// DeleteFlowLog menghapus satu catatan aliran milik user.
+func DeleteFlowLog(c *fiber.Ctx) error {
+	userUUID := c.Locals("user_id").(string)
+	params := c.Locals("request_params").(*dto.FlowLogParam)
+
+	var user models.User
+	if err := database.DB.First(&user, "uuid = ?", userUUID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	result := database.DB.Where("id = ? AND user_id = ?", params.ID, user.ID).Delete(&menstrual.FlowLog{})
+	if result.Error != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to delete flow log")
+	}
+	if result.RowsAffected == 0 {
+		return utils.SendError(c, fiber.StatusNotFound, "Flow log not found")
+	}
+
+	return utils.SendSuccess(c, fiber.StatusOK, "Flow log deleted successfully", nil)
+}
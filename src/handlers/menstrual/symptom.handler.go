@@ -4,10 +4,12 @@ import (
 	"errors"
 	"fmt"
 	"ipincamp/srikandi-sehat/database"
+	"ipincamp/srikandi-sehat/src/constants"
 	"ipincamp/srikandi-sehat/src/dto"
 	"ipincamp/srikandi-sehat/src/models"
 	"ipincamp/srikandi-sehat/src/models/menstrual"
 	"ipincamp/srikandi-sehat/src/utils"
+	"sort"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -109,11 +111,7 @@ func LogSymptoms(c *fiber.Ctx) error {
 		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to create symptom log")
 	}
 
-	var relevantCycle menstrual.MenstrualCycle
-	err := tx.Where("user_id = ? AND start_date <= ? AND (end_date IS NULL OR end_date >= ?)", user.ID, loggedAt, loggedAt).
-		Order("start_date desc").
-		First(&relevantCycle).Error
-
+	relevantCycle, err := findCycleForDate(tx, user.ID, loggedAt)
 	if err == nil {
 		tx.Model(&symptomLog).Update("menstrual_cycle_id", relevantCycle.ID)
 	}
@@ -127,6 +125,10 @@ func LogSymptoms(c *fiber.Ctx) error {
 			detail.SymptomOptionID.Int64 = int64(*s.SymptomOptionID)
 			detail.SymptomOptionID.Valid = true
 		}
+		if s.Severity != nil {
+			detail.Severity.Int16 = int16(*s.Severity)
+			detail.Severity.Valid = true
+		}
 		if err := tx.Create(&detail).Error; err != nil {
 			return utils.SendError(c, fiber.StatusInternalServerError, "Failed to log symptom detail")
 		}
@@ -231,13 +233,21 @@ func GetSymptomLogByID(c *fiber.Ctx) error {
 
 	var detailsDTO []dto.SymptomLogDetailResponse
 	var symptomIDs []uint
+	severityBySymptom := make(map[uint]int16)
 	for _, detail := range symptomLog.Details {
-		detailsDTO = append(detailsDTO, dto.SymptomLogDetailResponse{
+		detailDTO := dto.SymptomLogDetailResponse{
 			SymptomID:       detail.SymptomID,
 			SymptomName:     detail.Symptom.Name,
 			SymptomCategory: detail.Symptom.Category,
 			SelectedOption:  detail.SymptomOption.Name,
-		})
+		}
+		severity := int16(constants.SymptomSeverityDefault)
+		if detail.Severity.Valid {
+			detailDTO.Severity = &detail.Severity.Int16
+			severity = detail.Severity.Int16
+		}
+		severityBySymptom[detail.SymptomID] = max(severityBySymptom[detail.SymptomID], severity)
+		detailsDTO = append(detailsDTO, detailDTO)
 		symptomIDs = append(symptomIDs, detail.SymptomID)
 	}
 
@@ -248,6 +258,10 @@ func GetSymptomLogByID(c *fiber.Ctx) error {
 			Where("symptom_id IN ?", symptomIDs).
 			Find(&recommendations)
 	}
+	// Rekomendasi untuk gejala yang paling berat ditampilkan lebih dulu
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return severityBySymptom[recommendations[i].SymptomID] > severityBySymptom[recommendations[j].SymptomID]
+	})
 	var recommendationsDTO []dto.RecommendationResponse
 	for _, r := range recommendations {
 		recommendationsDTO = append(recommendationsDTO, dto.RecommendationResponse{
@@ -293,15 +307,18 @@ func GetRecommendationsBySymptoms(c *fiber.Ctx) error {
 	type SymptomFrequency struct {
 		SymptomID uint
 		Frequency int
+		Score     int
 	}
 	var frequentSymptoms []SymptomFrequency
 
+	// Gejala diurutkan berdasarkan skor berbobot keparahan, bukan hanya frekuensi,
+	// agar gejala berat yang jarang tetap mendapat rekomendasi.
 	err := database.DB.Model(&menstrual.SymptomLogDetail{}).
-		Select("symptom_id, COUNT(symptom_id) as frequency").
+		Select("symptom_id, COUNT(symptom_id) as frequency, SUM(? + COALESCE(severity, ?)) as score", constants.SymptomSeverityBaseWeight, constants.SymptomSeverityDefault).
 		Joins("JOIN symptom_logs ON symptom_logs.id = symptom_log_details.symptom_log_id").
 		Where("symptom_logs.user_id = ? AND symptom_logs.logged_at >= ?", user.ID, recentDate).
 		Group("symptom_id").
-		Order("frequency DESC").
+		Order("score DESC, frequency DESC").
 		Limit(4).
 		Scan(&frequentSymptoms).Error
 
@@ -314,8 +331,10 @@ func GetRecommendationsBySymptoms(c *fiber.Ctx) error {
 	}
 
 	var topSymptomIDs []uint
-	for _, s := range frequentSymptoms {
+	symptomRank := make(map[uint]int, len(frequentSymptoms))
+	for i, s := range frequentSymptoms {
 		topSymptomIDs = append(topSymptomIDs, s.SymptomID)
+		symptomRank[s.SymptomID] = i
 	}
 
 	var recommendations []menstrual.Recommendation
@@ -325,6 +344,9 @@ func GetRecommendationsBySymptoms(c *fiber.Ctx) error {
 		Find(&recommendations).Error; err != nil {
 		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to fetch recommendations")
 	}
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return symptomRank[recommendations[i].SymptomID] < symptomRank[recommendations[j].SymptomID]
+	})
 
 	var responseData []dto.RecommendationResponse
 	for _, r := range recommendations {
@@ -97,14 +97,20 @@ func maskEmail(email string) string {
 
 // --- Handlers ---
 
-// GenerateFullReportLink membuat token sekali pakai dan mengembalikan URL unduhan. (Admin only)
+// GenerateFullReportLink membuat token sekali pakai dan mengembalikan URL unduhan.
+// Untuk petugas berwilayah, batas wilayahnya ikut disimpan bersama token.
 func GenerateFullReportLink(c *fiber.Ctx) error {
+	regionCodes, _, err := utils.GetUserRegionScope(c.Locals("user_id").(string))
+	if err != nil {
+		return utils.SendError(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
 	token := uuid.New().String()
 	expiration := 5 * time.Minute // Tautan hanya valid selama 5 menit
 	expiresAt := time.Now().Add(expiration)
 
 	// Simpan token ke cache
-	utils.StoreReportToken(token, expiration)
+	utils.StoreScopedReportToken(token, expiration, regionCodes)
 
 	// Buat URL lengkap
 	downloadURL := fmt.Sprintf("%s/api/reports/download/%s", config.Get("APP_BASE_URL"), token)
@@ -121,7 +127,8 @@ func GenerateFullReportLink(c *fiber.Ctx) error {
 func DownloadFullReportByToken(c *fiber.Ctx) error {
 	// 1. Validasi token dari URL
 	token := c.Params("token")
-	if !utils.UseReportToken(token) {
+	regionCodes, valid := utils.UseScopedReportToken(token)
+	if !valid {
 		// Jika token tidak ada (sudah dipakai/kedaluwarsa), kirim error
 		return utils.SendError(c, fiber.StatusNotFound, "Link is invalid, has expired, or has already been used.")
 	}
@@ -132,13 +139,16 @@ func DownloadFullReportByToken(c *fiber.Ctx) error {
 		Joins("JOIN roles ON user_roles.role_id = roles.id").
 		Where("roles.name = ?", string(constants.AdminRole))
 
-	var cycles []menstrual.MenstrualCycle
-	err := database.DB.
+	cycleQuery := database.DB.
 		Preload("User.Profile.Village.Classification").
 		Preload("User.Profile.Village.District.Regency.Province").
-		Where("user_id NOT IN (?)", subQuery).
-		Order("user_id, start_date ASC").
-		Find(&cycles).Error
+		Where("user_id NOT IN (?)", subQuery)
+	if len(regionCodes) > 0 {
+		cycleQuery = cycleQuery.Where("user_id IN (?)", utils.UserIDsInRegions(regionCodes))
+	}
+
+	var cycles []menstrual.MenstrualCycle
+	err := cycleQuery.Order("user_id, start_date ASC").Find(&cycles).Error
 
 	if err != nil {
 		utils.ErrorLogger.Println("Failed to fetch cycle data for full export:", err)
@@ -0,0 +1,616 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"ipincamp/srikandi-sehat/database"
+	"ipincamp/srikandi-sehat/src/constants"
+	"ipincamp/srikandi-sehat/src/dto"
+	"ipincamp/srikandi-sehat/src/models"
+	"ipincamp/srikandi-sehat/src/utils"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// --- Helper functions for Role & Permission management ---
+
+// isSystemRole menandai role bawaan yang dipakai kode (registrasi, 2FA admin) sehingga tidak boleh diubah nama atau dihapus.
+func isSystemRole(name string) bool {
+	return name == string(constants.AdminRole) || name == string(constants.UserRole)
+}
+
+// findPermissionsByName mengambil permission berdasarkan nama. Mengembalikan daftar nama yang tidak ditemukan.
+func findPermissionsByName(tx *gorm.DB, names []string) ([]*models.Permission, []string, error) {
+	permissions := []*models.Permission{}
+	if len(names) == 0 {
+		return permissions, nil, nil
+	}
+
+	if err := tx.Where("name IN ?", names).Find(&permissions).Error; err != nil {
+		return nil, nil, err
+	}
+
+	var missing []string
+	for _, name := range names {
+		if !slices.ContainsFunc(permissions, func(p *models.Permission) bool { return p.Name == name }) {
+			missing = append(missing, name)
+		}
+	}
+	return permissions, missing, nil
+}
+
+func roleResponseJson(role models.Role, userCount int64) dto.RoleResponse {
+	permissionNames := make([]string, 0, len(role.Permissions))
+	for _, permission := range role.Permissions {
+		permissionNames = append(permissionNames, permission.Name)
+	}
+	sort.Strings(permissionNames)
+
+	response := dto.RoleResponse{
+		ID:          role.ID,
+		Name:        role.Name,
+		Permissions: permissionNames,
+		UserCount:   userCount,
+		IsSystem:    isSystemRole(role.Name),
+		CreatedAt:   role.CreatedAt,
+	}
+	if role.RegionCode.Valid {
+		response.RegionScope = &dto.RoleRegionScope{
+			Level: utils.RegionLevelOf(role.RegionCode.String),
+			Code:  role.RegionCode.String,
+		}
+	}
+	return response
+}
+
+// validateRoleRegionCode memastikan kode wilayah role terdaftar. Role sistem tidak boleh dibatasi wilayah.
+func validateRoleRegionCode(roleName, code string) error {
+	if code == "" {
+		return nil
+	}
+	if isSystemRole(roleName) {
+		return fiber.NewError(fiber.StatusForbidden, "System roles cannot be bound to a region")
+	}
+	exists, err := utils.RegionCodeExists(code)
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to look up region code %s: %v", code, err)
+		return fiber.NewError(fiber.StatusInternalServerError, "Failed to verify region code")
+	}
+	if !exists {
+		return fiber.NewError(fiber.StatusUnprocessableEntity, "Unknown region code")
+	}
+	return nil
+}
+
+func countRoleUsers(roleID uint) int64 {
+	var count int64
+	database.DB.Table("user_roles").Where("role_id = ?", roleID).Count(&count)
+	return count
+}
+
+// findUserForAccess mengambil user beserta role, permission role, dan permission langsung.
+// User di luar wilayah admin yang sedang login (jika berwilayah) dianggap tidak ada.
+func findUserForAccess(c *fiber.Ctx, userUUID string) (models.User, error) {
+	regionScope, err := applyAdminRegionScope(c, "users.id")
+	if err != nil {
+		return models.User{}, err
+	}
+
+	var user models.User
+	err = database.DB.Scopes(regionScope).Preload("Roles.Permissions").Preload("Permissions").First(&user, "uuid = ?", userUUID).Error
+	return user, err
+}
+
+// revokeSessionsWithoutTwoFactor mencabut semua sesi user yang kini memiliki akses administratif
+// tetapi belum mengaktifkan 2FA, karena sesi tersebut tidak pernah melewati langkah kedua.
+// Jika status akses tidak bisa dipastikan, sesi tetap dicabut.
+func revokeSessionsWithoutTwoFactor(user models.User) error {
+	if user.TwoFactorEnabledAt.Valid {
+		return nil
+	}
+	privileged, err := hasPrivilegedAccess(user)
+	if err == nil && !privileged {
+		return nil
+	}
+	return utils.RevokeAllUserTokens(user.ID, utils.SessionRevokedPrivilegeGrant)
+}
+
+// revokeRoleHoldersWithoutTwoFactor menerapkan revokeSessionsWithoutTwoFactor ke semua pemegang role
+// yang belum mengaktifkan 2FA, misalnya setelah permission role diganti.
+func revokeRoleHoldersWithoutTwoFactor(roleID uint) error {
+	holderIDs := database.DB.Table("user_roles").Select("user_id").Where("role_id = ?", roleID)
+
+	var users []models.User
+	if err := database.DB.Preload("Roles").
+		Where("id IN (?) AND two_factor_enabled_at IS NULL", holderIDs).
+		Find(&users).Error; err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		if err := revokeSessionsWithoutTwoFactor(user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func userAccessResponseJson(user models.User) dto.UserAccessResponse {
+	response := dto.UserAccessResponse{
+		UserID:               user.UUID,
+		Roles:                []string{},
+		DirectPermissions:    []string{},
+		EffectivePermissions: []string{},
+	}
+
+	effective := make(map[string]struct{})
+	for _, role := range user.Roles {
+		response.Roles = append(response.Roles, role.Name)
+		for _, permission := range role.Permissions {
+			effective[permission.Name] = struct{}{}
+		}
+	}
+	for _, permission := range user.Permissions {
+		response.DirectPermissions = append(response.DirectPermissions, permission.Name)
+		effective[permission.Name] = struct{}{}
+	}
+	for name := range effective {
+		response.EffectivePermissions = append(response.EffectivePermissions, name)
+	}
+
+	sort.Strings(response.Roles)
+	sort.Strings(response.DirectPermissions)
+	sort.Strings(response.EffectivePermissions)
+	return response
+}
+
+// reloadRoleCacheOrLog memuat ulang cache role; kegagalan hanya dicatat karena perubahan di DB sudah tersimpan.
+func reloadRoleCacheOrLog() {
+	if err := utils.ReloadRoleCache(); err != nil {
+		utils.ErrorLogger.Printf("Failed to reload role cache: %v", err)
+	}
+}
+
+// --- Role Handlers ---
+
+func GetAllRoles(c *fiber.Ctx) error {
+	var roles []models.Role
+	if err := database.DB.Preload("Permissions").Order("name ASC").Find(&roles).Error; err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to fetch roles")
+	}
+
+	response := make([]dto.RoleResponse, 0, len(roles))
+	for _, role := range roles {
+		response = append(response, roleResponseJson(role, countRoleUsers(role.ID)))
+	}
+
+	return utils.SendSuccess(c, fiber.StatusOK, "Roles fetched successfully", response)
+}
+
+func CreateRole(c *fiber.Ctx) error {
+	input := c.Locals("request_body").(*dto.CreateRoleRequest)
+
+	var existing int64
+	database.DB.Model(&models.Role{}).Where("name = ?", input.Name).Count(&existing)
+	if existing > 0 {
+		return utils.SendError(c, fiber.StatusConflict, "Role already exists")
+	}
+
+	if err := validateRoleRegionCode(input.Name, input.RegionCode); err != nil {
+		fiberErr := err.(*fiber.Error)
+		return utils.SendError(c, fiberErr.Code, fiberErr.Message)
+	}
+
+	role := models.Role{Name: input.Name}
+	if input.RegionCode != "" {
+		role.RegionCode = sql.NullString{String: input.RegionCode, Valid: true}
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		permissions, missing, err := findPermissionsByName(tx, input.Permissions)
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			return fiber.NewError(fiber.StatusUnprocessableEntity, fmt.Sprintf("Unknown permissions: %s", strings.Join(missing, ", ")))
+		}
+
+		role.Permissions = permissions
+		return tx.Create(&role).Error
+	})
+	if err != nil {
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			return utils.SendError(c, fiberErr.Code, fiberErr.Message)
+		}
+		utils.ErrorLogger.Printf("Failed to create role %s: %v", input.Name, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to create role")
+	}
+
+	reloadRoleCacheOrLog()
+
+	utils.InfoLogger.Printf("Role created by admin %s: %s", c.Locals("user_id"), role.Name)
+	return utils.SendSuccess(c, fiber.StatusCreated, "Role created successfully", roleResponseJson(role, 0))
+}
+
+func UpdateRole(c *fiber.Ctx) error {
+	params := c.Locals("request_params").(*dto.RoleParam)
+	input := c.Locals("request_body").(*dto.UpdateRoleRequest)
+
+	var role models.Role
+	if err := database.DB.Preload("Permissions").First(&role, params.ID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "Role not found")
+	}
+	previousPermissions := role.Permissions
+
+	// Role "user" dimiliki semua pengguna biasa; permission apa pun menjadikannya akses administratif.
+	if input.Permissions != nil && len(*input.Permissions) > 0 && role.Name == string(constants.UserRole) {
+		return utils.SendError(c, fiber.StatusForbidden, "The default user role cannot be granted administrative permissions")
+	}
+
+	renamed := input.Name != nil && *input.Name != role.Name
+	if renamed {
+		if isSystemRole(role.Name) {
+			return utils.SendError(c, fiber.StatusForbidden, "System roles cannot be renamed")
+		}
+		var existing int64
+		database.DB.Model(&models.Role{}).Where("name = ? AND id <> ?", *input.Name, role.ID).Count(&existing)
+		if existing > 0 {
+			return utils.SendError(c, fiber.StatusConflict, "Role already exists")
+		}
+	}
+
+	rescoped := input.RegionCode != nil && *input.RegionCode != role.RegionCode.String
+	if rescoped {
+		if err := validateRoleRegionCode(role.Name, *input.RegionCode); err != nil {
+			fiberErr := err.(*fiber.Error)
+			return utils.SendError(c, fiberErr.Code, fiberErr.Message)
+		}
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if renamed {
+			if err := tx.Model(&role).Update("name", *input.Name).Error; err != nil {
+				return err
+			}
+		}
+
+		if rescoped {
+			regionCode := sql.NullString{String: *input.RegionCode, Valid: *input.RegionCode != ""}
+			if err := tx.Model(&role).Update("region_code", regionCode).Error; err != nil {
+				return err
+			}
+		}
+
+		if input.Permissions != nil {
+			permissions, missing, err := findPermissionsByName(tx, *input.Permissions)
+			if err != nil {
+				return err
+			}
+			if len(missing) > 0 {
+				return fiber.NewError(fiber.StatusUnprocessableEntity, fmt.Sprintf("Unknown permissions: %s", strings.Join(missing, ", ")))
+			}
+			if err := tx.Model(&role).Association("Permissions").Replace(permissions); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			return utils.SendError(c, fiberErr.Code, fiberErr.Message)
+		}
+		utils.ErrorLogger.Printf("Failed to update role %d: %v", role.ID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to update role")
+	}
+
+	if renamed {
+		reloadRoleCacheOrLog()
+		utils.InvalidateAllUserRolesCache()
+	} else if rescoped {
+		// Batas wilayah dibaca dari cache role, jadi cukup memuat ulang cache tersebut.
+		reloadRoleCacheOrLog()
+	}
+	if !renamed && input.Permissions != nil {
+		utils.InvalidateAllUserPermissionsCache()
+	}
+
+	if input.Permissions != nil {
+		if err := revokeRoleHoldersWithoutTwoFactor(role.ID); err != nil {
+			// Kembalikan permission lama agar pemegang role tanpa 2FA tidak mendapat akses admin.
+			utils.ErrorLogger.Printf("Failed to revoke sessions of role %d holders, rolling back permissions: %v", role.ID, err)
+			if err := database.DB.Model(&role).Association("Permissions").Replace(previousPermissions); err != nil {
+				utils.ErrorLogger.Printf("Failed to roll back permissions of role %d: %v", role.ID, err)
+			}
+			utils.InvalidateAllUserPermissionsCache()
+			return utils.SendError(c, fiber.StatusInternalServerError, "Failed to update role permissions")
+		}
+	}
+
+	database.DB.Preload("Permissions").First(&role, role.ID)
+
+	utils.InfoLogger.Printf("Role updated by admin %s: %s", c.Locals("user_id"), role.Name)
+	return utils.SendSuccess(c, fiber.StatusOK, "Role updated successfully", roleResponseJson(role, countRoleUsers(role.ID)))
+}
+
+func DeleteRole(c *fiber.Ctx) error {
+	params := c.Locals("request_params").(*dto.RoleParam)
+
+	var role models.Role
+	if err := database.DB.First(&role, params.ID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "Role not found")
+	}
+
+	if isSystemRole(role.Name) {
+		return utils.SendError(c, fiber.StatusForbidden, "System roles cannot be deleted")
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&role).Association("Permissions").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM user_roles WHERE role_id = ?", role.ID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&role).Error
+	})
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to delete role %d: %v", role.ID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to delete role")
+	}
+
+	reloadRoleCacheOrLog()
+	utils.InvalidateAllUserRolesCache()
+
+	utils.InfoLogger.Printf("Role deleted by admin %s: %s", c.Locals("user_id"), role.Name)
+	return utils.SendSuccess(c, fiber.StatusOK, "Role deleted successfully", nil)
+}
+
+// --- Permission Handlers ---
+
+func GetAllPermissions(c *fiber.Ctx) error {
+	var permissions []models.Permission
+	if err := database.DB.Order("name ASC").Find(&permissions).Error; err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to fetch permissions")
+	}
+
+	response := make([]dto.PermissionResponse, 0, len(permissions))
+	for _, permission := range permissions {
+		response = append(response, dto.PermissionResponseJson(permission))
+	}
+
+	return utils.SendSuccess(c, fiber.StatusOK, "Permissions fetched successfully", response)
+}
+
+func CreatePermission(c *fiber.Ctx) error {
+	input := c.Locals("request_body").(*dto.PermissionRequest)
+
+	var existing int64
+	database.DB.Model(&models.Permission{}).Where("name = ?", input.Name).Count(&existing)
+	if existing > 0 {
+		return utils.SendError(c, fiber.StatusConflict, "Permission already exists")
+	}
+
+	permission := models.Permission{Name: input.Name}
+	if err := database.DB.Create(&permission).Error; err != nil {
+		utils.ErrorLogger.Printf("Failed to create permission %s: %v", input.Name, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to create permission")
+	}
+
+	utils.InfoLogger.Printf("Permission created by admin %s: %s", c.Locals("user_id"), permission.Name)
+	return utils.SendSuccess(c, fiber.StatusCreated, "Permission created successfully", dto.PermissionResponseJson(permission))
+}
+
+func UpdatePermission(c *fiber.Ctx) error {
+	params := c.Locals("request_params").(*dto.PermissionParam)
+	input := c.Locals("request_body").(*dto.PermissionRequest)
+
+	var permission models.Permission
+	if err := database.DB.First(&permission, params.ID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "Permission not found")
+	}
+
+	var existing int64
+	database.DB.Model(&models.Permission{}).Where("name = ? AND id <> ?", input.Name, permission.ID).Count(&existing)
+	if existing > 0 {
+		return utils.SendError(c, fiber.StatusConflict, "Permission already exists")
+	}
+
+	if err := database.DB.Model(&permission).Update("name", input.Name).Error; err != nil {
+		utils.ErrorLogger.Printf("Failed to update permission %d: %v", permission.ID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to update permission")
+	}
+	utils.InvalidateAllUserPermissionsCache()
+
+	utils.InfoLogger.Printf("Permission updated by admin %s: %s", c.Locals("user_id"), permission.Name)
+	return utils.SendSuccess(c, fiber.StatusOK, "Permission updated successfully", dto.PermissionResponseJson(permission))
+}
+
+func DeletePermission(c *fiber.Ctx) error {
+	params := c.Locals("request_params").(*dto.PermissionParam)
+
+	var permission models.Permission
+	if err := database.DB.First(&permission, params.ID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "Permission not found")
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, joinTable := range []string{"role_permissions", "user_permissions"} {
+			if err := tx.Exec("DELETE FROM "+joinTable+" WHERE permission_id = ?", permission.ID).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(&permission).Error
+	})
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to delete permission %d: %v", permission.ID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to delete permission")
+	}
+	utils.InvalidateAllUserPermissionsCache()
+
+	utils.InfoLogger.Printf("Permission deleted by admin %s: %s", c.Locals("user_id"), permission.Name)
+	return utils.SendSuccess(c, fiber.StatusOK, "Permission deleted successfully", nil)
+}
+
+// --- User Access Handlers ---
+
+// GetUserAccess menampilkan role, permission langsung, dan permission efektif milik user.
+func GetUserAccess(c *fiber.Ctx) error {
+	params := c.Locals("request_params").(*dto.UserParam)
+
+	user, err := findUserForAccess(c, params.ID)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	return utils.SendSuccess(c, fiber.StatusOK, "User access fetched successfully", userAccessResponseJson(user))
+}
+
+func AssignRoleToUser(c *fiber.Ctx) error {
+	params := c.Locals("request_params").(*dto.UserParam)
+	input := c.Locals("request_body").(*dto.AssignRoleRequest)
+
+	user, err := findUserForAccess(c, params.ID)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	role, err := utils.GetRoleByName(input.Role)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "Role not found")
+	}
+
+	if slices.ContainsFunc(user.Roles, func(r *models.Role) bool { return r.ID == role.ID }) {
+		return utils.SendError(c, fiber.StatusConflict, "User already has this role")
+	}
+
+	if err := database.DB.Model(&user).Association("Roles").Append(&role); err != nil {
+		utils.ErrorLogger.Printf("Failed to assign role %s to %s: %v", role.Name, user.UUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to assign role")
+	}
+	utils.InvalidateUserRolesCache(user.UUID)
+
+	if reloaded, err := findUserForAccess(c, user.UUID); err == nil {
+		user = reloaded
+	}
+	if err := revokeSessionsWithoutTwoFactor(user); err != nil {
+		// Batalkan penugasan role agar user tidak memegang akses admin dengan sesi tanpa 2FA.
+		utils.ErrorLogger.Printf("Failed to revoke sessions of %s after role assignment, rolling back: %v", user.UUID, err)
+		if err := database.DB.Model(&user).Association("Roles").Delete(&role); err != nil {
+			utils.ErrorLogger.Printf("Failed to roll back role %s from %s: %v", role.Name, user.UUID, err)
+		}
+		utils.InvalidateUserRolesCache(user.UUID)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to assign role")
+	}
+	utils.AuthLogger.Printf("Role %s assigned to %s by admin %s", role.Name, user.UUID, c.Locals("user_id"))
+	return utils.SendSuccess(c, fiber.StatusOK, "Role assigned successfully", userAccessResponseJson(user))
+}
+
+func RevokeRoleFromUser(c *fiber.Ctx) error {
+	params := c.Locals("request_params").(*dto.UserRoleParam)
+	adminUUID := c.Locals("user_id").(string)
+
+	user, err := findUserForAccess(c, params.ID)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	index := slices.IndexFunc(user.Roles, func(r *models.Role) bool { return r.Name == params.Role })
+	if index < 0 {
+		return utils.SendError(c, fiber.StatusNotFound, "User does not have this role")
+	}
+	role := user.Roles[index]
+
+	if role.Name == string(constants.AdminRole) {
+		if user.UUID == adminUUID {
+			return utils.SendError(c, fiber.StatusForbidden, "You cannot revoke your own admin role")
+		}
+		if countRoleUsers(role.ID) <= 1 {
+			return utils.SendError(c, fiber.StatusForbidden, "Cannot revoke the admin role from the last admin")
+		}
+	}
+
+	if err := database.DB.Model(&user).Association("Roles").Delete(role); err != nil {
+		utils.ErrorLogger.Printf("Failed to revoke role %s from %s: %v", role.Name, user.UUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to revoke role")
+	}
+	utils.InvalidateUserRolesCache(user.UUID)
+
+	if reloaded, err := findUserForAccess(c, user.UUID); err == nil {
+		user = reloaded
+	}
+	utils.AuthLogger.Printf("Role %s revoked from %s by admin %s", role.Name, user.UUID, adminUUID)
+	return utils.SendSuccess(c, fiber.StatusOK, "Role revoked successfully", userAccessResponseJson(user))
+}
+
+func GrantPermissionToUser(c *fiber.Ctx) error {
+	params := c.Locals("request_params").(*dto.UserParam)
+	input := c.Locals("request_body").(*dto.AssignPermissionRequest)
+
+	user, err := findUserForAccess(c, params.ID)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	var permission models.Permission
+	if err := database.DB.First(&permission, "name = ?", input.Permission).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "Permission not found")
+	}
+
+	if slices.ContainsFunc(user.Permissions, func(p *models.Permission) bool { return p.ID == permission.ID }) {
+		return utils.SendError(c, fiber.StatusConflict, "User already has this permission")
+	}
+
+	if err := database.DB.Model(&user).Association("Permissions").Append(&permission); err != nil {
+		utils.ErrorLogger.Printf("Failed to grant permission %s to %s: %v", permission.Name, user.UUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to grant permission")
+	}
+	utils.InvalidateUserPermissionsCache(user.UUID)
+
+	if reloaded, err := findUserForAccess(c, user.UUID); err == nil {
+		user = reloaded
+	}
+	if err := revokeSessionsWithoutTwoFactor(user); err != nil {
+		// Batalkan pemberian permission agar user tidak memegang akses admin dengan sesi tanpa 2FA.
+		utils.ErrorLogger.Printf("Failed to revoke sessions of %s after permission grant, rolling back: %v", user.UUID, err)
+		if err := database.DB.Model(&user).Association("Permissions").Delete(&permission); err != nil {
+			utils.ErrorLogger.Printf("Failed to roll back permission %s from %s: %v", permission.Name, user.UUID, err)
+		}
+		utils.InvalidateUserPermissionsCache(user.UUID)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to grant permission")
+	}
+	utils.AuthLogger.Printf("Permission %s granted to %s by admin %s", permission.Name, user.UUID, c.Locals("user_id"))
+	return utils.SendSuccess(c, fiber.StatusOK, "Permission granted successfully", userAccessResponseJson(user))
+}
+
+func RevokePermissionFromUser(c *fiber.Ctx) error {
+	params := c.Locals("request_params").(*dto.UserPermissionParam)
+
+	user, err := findUserForAccess(c, params.ID)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	index := slices.IndexFunc(user.Permissions, func(p *models.Permission) bool { return p.Name == params.Permission })
+	if index < 0 {
+		return utils.SendError(c, fiber.StatusNotFound, "User does not have this direct permission")
+	}
+	permission := user.Permissions[index]
+
+	if err := database.DB.Model(&user).Association("Permissions").Delete(permission); err != nil {
+		utils.ErrorLogger.Printf("Failed to revoke permission %s from %s: %v", permission.Name, user.UUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to revoke permission")
+	}
+	utils.InvalidateUserPermissionsCache(user.UUID)
+
+	if reloaded, err := findUserForAccess(c, user.UUID); err == nil {
+		user = reloaded
+	}
+	utils.AuthLogger.Printf("Permission %s revoked from %s by admin %s", permission.Name, user.UUID, c.Locals("user_id"))
+	return utils.SendSuccess(c, fiber.StatusOK, "Permission revoked successfully", userAccessResponseJson(user))
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"ipincamp/srikandi-sehat/database"
+	"ipincamp/srikandi-sehat/src/dto"
+	"ipincamp/srikandi-sehat/src/models"
+	"ipincamp/srikandi-sehat/src/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetMySessions menampilkan semua sesi login aktif milik user.
+// LastSeenAt diperbarui setiap kali refresh token dirotasi.
+func GetMySessions(c *fiber.Ctx) error {
+	userUUID := c.Locals("user_id").(string)
+	currentSessionUUID, _ := c.Locals("session_id").(string)
+
+	var user models.User
+	if err := database.DB.First(&user, "uuid = ?", userUUID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	var sessions []models.Session
+	if err := database.DB.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", user.ID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error; err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to fetch sessions")
+	}
+
+	responseData := make([]dto.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		responseData = append(responseData, dto.SessionResponse{
+			ID:         session.UUID,
+			DeviceName: session.DeviceName,
+			IPAddress:  session.IPAddress,
+			UserAgent:  session.UserAgent,
+			IsCurrent:  session.UUID == currentSessionUUID,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+		})
+	}
+
+	return utils.SendSuccess(c, fiber.StatusOK, "Active sessions fetched successfully", responseData)
+}
+
+// RevokeMySession mengeluarkan satu sesi milik user (misalnya ponsel lama).
+func RevokeMySession(c *fiber.Ctx) error {
+	userUUID := c.Locals("user_id").(string)
+	params := c.Locals("request_params").(*dto.SessionParam)
+
+	var user models.User
+	if err := database.DB.First(&user, "uuid = ?", userUUID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	var session models.Session
+	if err := database.DB.First(&session, "uuid = ? AND user_id = ? AND revoked_at IS NULL", params.ID, user.ID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "Session not found")
+	}
+
+	if err := utils.RevokeSession(session.UUID, utils.SessionRevokedByUser); err != nil {
+		utils.ErrorLogger.Printf("Failed to revoke session %s for %s: %v", session.UUID, userUUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to revoke session")
+	}
+
+	utils.AuthLogger.Printf("Session revoked by user: %s (session: %s)", userUUID, session.UUID)
+	return utils.SendSuccess(c, fiber.StatusOK, "Session revoked successfully", nil)
+}
+
+// RevokeOtherSessions mengeluarkan semua sesi milik user kecuali sesi yang sedang dipakai.
+func RevokeOtherSessions(c *fiber.Ctx) error {
+	userUUID := c.Locals("user_id").(string)
+	currentSessionUUID, ok := c.Locals("session_id").(string)
+	if !ok {
+		return utils.SendError(c, fiber.StatusUnauthorized, "Invalid session in token")
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, "uuid = ?", userUUID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	revoked, err := utils.RevokeOtherUserSessions(user.ID, currentSessionUUID, utils.SessionRevokedByUser)
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to revoke other sessions for %s: %v", userUUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to revoke sessions")
+	}
+
+	utils.AuthLogger.Printf("Other sessions revoked by user: %s (%d sessions)", userUUID, revoked)
+	return utils.SendSuccess(c, fiber.StatusOK, "Other sessions revoked successfully", fiber.Map{
+		"revoked_sessions": revoked,
+	})
+}
@@ -0,0 +1,479 @@
+package handlers
+
+import (
+	"ipincamp/srikandi-sehat/database"
+	"ipincamp/srikandi-sehat/src/constants"
+	"ipincamp/srikandi-sehat/src/dto"
+	"ipincamp/srikandi-sehat/src/models"
+	"ipincamp/srikandi-sehat/src/utils"
+	"slices"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const twoFactorRecoveryCodeCount = 10
+
+// --- Helper functions for Two-Factor Authentication ---
+
+// userHasRole memeriksa role dari user yang sudah di-preload "Roles".
+func userHasRole(user models.User, role constants.RoleName) bool {
+	return slices.ContainsFunc(user.Roles, func(r *models.Role) bool {
+		return r.Name == string(role)
+	})
+}
+
+// hasPrivilegedAccess bernilai true untuk admin dan user yang memiliki permission administratif apa pun.
+// Error saat membaca permission dikembalikan agar pemanggil menolak (fail closed), bukan menganggap user biasa.
+func hasPrivilegedAccess(user models.User) (bool, error) {
+	if userHasRole(user, constants.AdminRole) {
+		return true, nil
+	}
+	permissions, err := utils.GetUserPermissions(user.UUID)
+	if err != nil {
+		return false, err
+	}
+	return len(permissions) > 0, nil
+}
+
+// requiresTwoFactor menentukan apakah login user wajib melewati langkah kedua.
+// Admin dan pemegang permission administratif selalu wajib; user biasa hanya jika sudah mengaktifkan 2FA.
+func requiresTwoFactor(user models.User) (bool, error) {
+	if user.TwoFactorEnabledAt.Valid {
+		return true, nil
+	}
+	return hasPrivilegedAccess(user)
+}
+
+// sendTwoFactorChallenge mengirim token tantangan sebagai pengganti JWT setelah password benar.
+func sendTwoFactorChallenge(c *fiber.Ctx, user models.User, deviceName string) error {
+	challengeToken, expiresAt, err := utils.GenerateTwoFactorChallenge(user, deviceName)
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to create 2FA challenge for %s: %v", user.UUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to generate JWT token")
+	}
+
+	response := dto.TwoFactorChallengeResponse{
+		TwoFactorRequired: true,
+		SetupRequired:     !user.TwoFactorEnabledAt.Valid,
+		ChallengeToken:    challengeToken,
+		ExpiresAt:         expiresAt,
+	}
+
+	utils.AuthLogger.Printf("Login password accepted, 2FA challenge issued: %s (setup required: %v)", user.UUID, response.SetupRequired)
+	return utils.SendSuccess(c, fiber.StatusOK, "Two-factor authentication required", response)
+}
+
+// rejectTwoFactorAttempt mencatat kode 2FA yang salah sebagai login gagal untuk akun (agar tebakan
+// dari banyak IP tetap tertahan) dan untuk token tantangan. Tantangan dicabut setelah
+// constants.TwoFactorChallengeMaxAttempts kali salah.
+func rejectTwoFactorAttempt(c *fiber.Ctx, user models.User, challenge utils.TwoFactorChallenge, message string) error {
+	lockedUntil, locked, err := utils.RecordLoginFailure(models.LoginLockoutScopeAccount, user.UUID, constants.LoginMaxFailedAttempts)
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to record 2FA failure for %s: %v", user.UUID, err)
+	}
+	if locked {
+		utils.AuthLogger.Printf("Account locked until %s after repeated failed 2FA attempts: %s", lockedUntil.Format(time.RFC3339), user.UUID)
+		if err := utils.RevokeTwoFactorChallenge(challenge); err != nil {
+			utils.ErrorLogger.Printf("Failed to revoke 2FA challenge for %s: %v", user.UUID, err)
+		}
+		go func(email string) {
+			if err := utils.SendAccountLockedEmail(email, lockedUntil); err != nil {
+				utils.ErrorLogger.Printf("Failed to send account locked email to %s: %v", email, err)
+			}
+		}(user.Email)
+		return utils.SendError(c, fiber.StatusLocked, loginLockedMessage(lockedUntil))
+	}
+
+	_, exhausted, err := utils.RecordLoginFailure(models.LoginLockoutScopeTwoFactorChallenge, challenge.JTI, constants.TwoFactorChallengeMaxAttempts)
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to record 2FA challenge failure for %s: %v", user.UUID, err)
+	}
+	if exhausted {
+		if err := utils.RevokeTwoFactorChallenge(challenge); err != nil {
+			utils.ErrorLogger.Printf("Failed to revoke 2FA challenge for %s: %v", user.UUID, err)
+		}
+		utils.AuthLogger.Printf("2FA challenge revoked after %d failed attempts: %s", constants.TwoFactorChallengeMaxAttempts, user.UUID)
+		return utils.SendError(c, fiber.StatusUnauthorized, "Too many invalid codes. Please log in again")
+	}
+
+	return utils.SendError(c, fiber.StatusUnauthorized, message)
+}
+
+// startTwoFactorSetup membuat secret TOTP baru (belum aktif) dan menyimpannya terenkripsi.
+func startTwoFactorSetup(user models.User) (dto.TwoFactorSetupResponse, error) {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return dto.TwoFactorSetupResponse{}, err
+	}
+
+	encryptedSecret, err := utils.EncryptTwoFactorSecret(secret)
+	if err != nil {
+		return dto.TwoFactorSetupResponse{}, err
+	}
+
+	updates := map[string]interface{}{
+		"two_factor_secret":    encryptedSecret,
+		"two_factor_last_step": 0,
+	}
+	if err := database.DB.Model(&user).Updates(updates).Error; err != nil {
+		return dto.TwoFactorSetupResponse{}, err
+	}
+
+	return dto.TwoFactorSetupResponse{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(secret, user.Email),
+	}, nil
+}
+
+// checkTwoFactorCode memvalidasi kode TOTP dan mencatat time step yang dipakai agar tidak bisa di-replay.
+func checkTwoFactorCode(user models.User, code string) (bool, error) {
+	if !user.TwoFactorSecret.Valid {
+		return false, nil
+	}
+
+	secret, err := utils.DecryptTwoFactorSecret(user.TwoFactorSecret.String)
+	if err != nil {
+		return false, err
+	}
+
+	step, ok := utils.ValidateTOTP(secret, code, user.TwoFactorLastStep)
+	if !ok {
+		return false, nil
+	}
+
+	// Update bersyarat agar dua request paralel dengan kode yang sama tidak sama-sama lolos.
+	result := database.DB.Model(&models.User{}).
+		Where("id = ? AND two_factor_last_step < ?", user.ID, step).
+		Update("two_factor_last_step", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// useRecoveryCode menandai kode pemulihan sebagai terpakai jika valid.
+func useRecoveryCode(user models.User, code string) (bool, error) {
+	result := database.DB.Model(&models.TwoFactorRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, utils.HashRecoveryCode(code)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// replaceRecoveryCodes menghapus kode pemulihan lama dan membuat set baru.
+// Kode plaintext hanya dikembalikan sekali ke user.
+func replaceRecoveryCodes(tx *gorm.DB, userID uint) ([]string, error) {
+	codes, err := utils.GenerateRecoveryCodes(twoFactorRecoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Where("user_id = ?", userID).Delete(&models.TwoFactorRecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	records := make([]models.TwoFactorRecoveryCode, 0, len(codes))
+	for _, code := range codes {
+		records = append(records, models.TwoFactorRecoveryCode{
+			UserID:   userID,
+			CodeHash: utils.HashRecoveryCode(code),
+		})
+	}
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// enableTwoFactor mengaktifkan 2FA untuk secret yang sedang di-setup dan membuat kode pemulihan.
+func enableTwoFactor(user models.User) ([]string, error) {
+	var codes []string
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("two_factor_enabled_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	return codes, err
+}
+
+// --- Handlers (login step) ---
+
+// SetupTwoFactorChallenge memulai pendaftaran TOTP saat login, untuk admin yang belum mengaktifkan 2FA.
+func SetupTwoFactorChallenge(c *fiber.Ctx) error {
+	input := c.Locals("request_body").(*dto.TwoFactorChallengeRequest)
+
+	challenge, err := utils.ParseTwoFactorChallenge(input.ChallengeToken)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusUnauthorized, "Invalid or expired challenge token")
+	}
+	userUUID := challenge.UserUUID
+
+	var user models.User
+	if err := database.DB.First(&user, "uuid = ?", userUUID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	if user.TwoFactorEnabledAt.Valid {
+		return utils.SendError(c, fiber.StatusConflict, "Two-factor authentication is already enabled")
+	}
+
+	response, err := startTwoFactorSetup(user)
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to start 2FA setup for %s: %v", userUUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to start two-factor setup")
+	}
+
+	utils.AuthLogger.Printf("2FA setup started during login: %s", userUUID)
+	return utils.SendSuccess(c, fiber.StatusOK, "Scan the QR code with your authenticator app, then verify the code", response)
+}
+
+// VerifyTwoFactorChallenge menyelesaikan login dengan kode TOTP atau kode pemulihan, lalu menerbitkan sesi.
+func VerifyTwoFactorChallenge(c *fiber.Ctx) error {
+	input := c.Locals("request_body").(*dto.TwoFactorVerifyRequest)
+
+	challenge, err := utils.ParseTwoFactorChallenge(input.ChallengeToken)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusUnauthorized, "Invalid or expired challenge token")
+	}
+	userUUID := challenge.UserUUID
+
+	var user models.User
+	if err := database.DB.Preload("Roles").Preload("Profile").First(&user, "uuid = ?", userUUID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	if lockedUntil, locked := utils.GetLoginLockout(models.LoginLockoutScopeAccount, user.UUID); locked {
+		utils.AuthLogger.Printf("2FA blocked (account locked until %s): %s", lockedUntil.Format(time.RFC3339), userUUID)
+		return utils.SendError(c, fiber.StatusLocked, loginLockedMessage(lockedUntil))
+	}
+
+	var recoveryCodes []string
+	switch {
+	case user.TwoFactorEnabledAt.Valid && input.RecoveryCode != "":
+		ok, err := useRecoveryCode(user, input.RecoveryCode)
+		if err != nil {
+			return utils.SendError(c, fiber.StatusInternalServerError, "Failed to verify recovery code")
+		}
+		if !ok {
+			utils.AuthLogger.Printf("2FA failed (invalid recovery code): %s", userUUID)
+			return rejectTwoFactorAttempt(c, user, challenge, "Invalid recovery code")
+		}
+		utils.AuthLogger.Printf("2FA recovery code used: %s", userUUID)
+
+	case input.Code != "":
+		ok, err := checkTwoFactorCode(user, input.Code)
+		if err != nil {
+			utils.ErrorLogger.Printf("Failed to verify 2FA code for %s: %v", userUUID, err)
+			return utils.SendError(c, fiber.StatusInternalServerError, "Failed to verify two-factor code")
+		}
+		if !ok {
+			utils.AuthLogger.Printf("2FA failed (invalid code): %s", userUUID)
+			return rejectTwoFactorAttempt(c, user, challenge, "Invalid two-factor code")
+		}
+
+		// Kode pertama yang valid sekaligus mengaktifkan 2FA untuk admin yang baru mendaftar.
+		if !user.TwoFactorEnabledAt.Valid {
+			recoveryCodes, err = enableTwoFactor(user)
+			if err != nil {
+				utils.ErrorLogger.Printf("Failed to enable 2FA for %s: %v", userUUID, err)
+				return utils.SendError(c, fiber.StatusInternalServerError, "Failed to enable two-factor authentication")
+			}
+			utils.AuthLogger.Printf("2FA enabled during login: %s", userUUID)
+		}
+
+	default:
+		return utils.SendError(c, fiber.StatusBadRequest, "Two-factor setup has not been completed")
+	}
+
+	// Token tantangan hanya boleh ditukar satu kali.
+	if err := utils.RevokeTwoFactorChallenge(challenge); err != nil {
+		utils.ErrorLogger.Printf("Failed to revoke 2FA challenge for %s: %v", userUUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to generate JWT token")
+	}
+	if err := utils.ResetLoginFailures(models.LoginLockoutScopeAccount, user.UUID); err != nil {
+		utils.ErrorLogger.Printf("Failed to reset login failures for %s: %v", user.UUID, err)
+	}
+
+	token, refreshToken, err := utils.CreateSession(user, challenge.DeviceName, c.Get(fiber.HeaderUserAgent), c.IP())
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to create session for %s: %v", user.Email, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to generate JWT token")
+	}
+
+	go utils.AddUserToFrequentLoginFilter(user)
+	responseData := dto.UserResponseJson(user, token)
+	responseData.RefreshToken = refreshToken
+	responseData.RecoveryCodes = recoveryCodes
+
+	utils.AuthLogger.Printf("User login successful (2FA): %s (UUID: %s)", responseData.Email, responseData.ID)
+	return utils.SendSuccess(c, fiber.StatusOK, "Login successful", responseData)
+}
+
+// --- Handlers (account settings) ---
+
+// SetupMyTwoFactor memulai pendaftaran TOTP dari pengaturan akun (opt-in untuk user biasa).
+func SetupMyTwoFactor(c *fiber.Ctx) error {
+	userUUID := c.Locals("user_id").(string)
+
+	var user models.User
+	if err := database.DB.First(&user, "uuid = ?", userUUID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	if user.TwoFactorEnabledAt.Valid {
+		return utils.SendError(c, fiber.StatusConflict, "Two-factor authentication is already enabled")
+	}
+
+	response, err := startTwoFactorSetup(user)
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to start 2FA setup for %s: %v", userUUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to start two-factor setup")
+	}
+
+	utils.AuthLogger.Printf("2FA setup started: %s", userUUID)
+	return utils.SendSuccess(c, fiber.StatusOK, "Scan the QR code with your authenticator app, then verify the code", response)
+}
+
+// EnableMyTwoFactor mengaktifkan 2FA setelah user memasukkan kode pertama dari aplikasi authenticator.
+func EnableMyTwoFactor(c *fiber.Ctx) error {
+	userUUID := c.Locals("user_id").(string)
+	input := c.Locals("request_body").(*dto.TwoFactorCodeRequest)
+
+	var user models.User
+	if err := database.DB.First(&user, "uuid = ?", userUUID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	if user.TwoFactorEnabledAt.Valid {
+		return utils.SendError(c, fiber.StatusConflict, "Two-factor authentication is already enabled")
+	}
+	if !user.TwoFactorSecret.Valid {
+		return utils.SendError(c, fiber.StatusBadRequest, "Two-factor setup has not been started")
+	}
+
+	ok, err := checkTwoFactorCode(user, input.Code)
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to verify 2FA code for %s: %v", userUUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to verify two-factor code")
+	}
+	if !ok {
+		utils.AuthLogger.Printf("2FA enable failed (invalid code): %s", userUUID)
+		return utils.SendError(c, fiber.StatusUnauthorized, "Invalid two-factor code")
+	}
+
+	recoveryCodes, err := enableTwoFactor(user)
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to enable 2FA for %s: %v", userUUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to enable two-factor authentication")
+	}
+
+	utils.AuthLogger.Printf("2FA enabled: %s", userUUID)
+	return utils.SendSuccess(c, fiber.StatusOK, "Two-factor authentication enabled. Store these recovery codes in a safe place.", dto.RecoveryCodesResponse{
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+// DisableMyTwoFactor mematikan 2FA. Admin dan pemegang permission administratif tidak boleh mematikannya.
+func DisableMyTwoFactor(c *fiber.Ctx) error {
+	userUUID := c.Locals("user_id").(string)
+	input := c.Locals("request_body").(*dto.DisableTwoFactorRequest)
+
+	var user models.User
+	if err := database.DB.Preload("Roles").First(&user, "uuid = ?", userUUID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	privileged, err := hasPrivilegedAccess(user)
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to check privileged access for %s: %v", userUUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to verify account permissions")
+	}
+	if privileged {
+		return utils.SendError(c, fiber.StatusForbidden, "Two-factor authentication is mandatory for admin accounts")
+	}
+	if !user.TwoFactorEnabledAt.Valid {
+		return utils.SendError(c, fiber.StatusConflict, "Two-factor authentication is not enabled")
+	}
+
+	match, err := utils.CheckPasswordHash(input.Password, user.Password)
+	if err != nil || !match {
+		utils.AuthLogger.Printf("2FA disable failed (invalid password): %s", userUUID)
+		return utils.SendError(c, fiber.StatusUnauthorized, "Password is incorrect")
+	}
+
+	ok, err := checkTwoFactorCode(user, input.Code)
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to verify 2FA code for %s: %v", userUUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to verify two-factor code")
+	}
+	if !ok {
+		utils.AuthLogger.Printf("2FA disable failed (invalid code): %s", userUUID)
+		return utils.SendError(c, fiber.StatusUnauthorized, "Invalid two-factor code")
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{
+			"two_factor_secret":     nil,
+			"two_factor_enabled_at": nil,
+			"two_factor_last_step":  0,
+		}
+		if err := tx.Model(&user).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", user.ID).Delete(&models.TwoFactorRecoveryCode{}).Error
+	})
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to disable two-factor authentication")
+	}
+
+	utils.AuthLogger.Printf("2FA disabled: %s", userUUID)
+	return utils.SendSuccess(c, fiber.StatusOK, "Two-factor authentication disabled", nil)
+}
+
+// RegenerateMyRecoveryCodes mengganti semua kode pemulihan; kode lama tidak berlaku lagi.
+func RegenerateMyRecoveryCodes(c *fiber.Ctx) error {
+	userUUID := c.Locals("user_id").(string)
+	input := c.Locals("request_body").(*dto.TwoFactorCodeRequest)
+
+	var user models.User
+	if err := database.DB.First(&user, "uuid = ?", userUUID).Error; err != nil {
+		return utils.SendError(c, fiber.StatusNotFound, "User not found")
+	}
+
+	if !user.TwoFactorEnabledAt.Valid {
+		return utils.SendError(c, fiber.StatusConflict, "Two-factor authentication is not enabled")
+	}
+
+	ok, err := checkTwoFactorCode(user, input.Code)
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to verify 2FA code for %s: %v", userUUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to verify two-factor code")
+	}
+	if !ok {
+		return utils.SendError(c, fiber.StatusUnauthorized, "Invalid two-factor code")
+	}
+
+	var recoveryCodes []string
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		recoveryCodes, err = replaceRecoveryCodes(tx, user.ID)
+		return err
+	})
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to regenerate recovery codes")
+	}
+
+	utils.AuthLogger.Printf("2FA recovery codes regenerated: %s", userUUID)
+	return utils.SendSuccess(c, fiber.StatusOK, "Recovery codes regenerated", dto.RecoveryCodesResponse{
+		RecoveryCodes: recoveryCodes,
+	})
+}
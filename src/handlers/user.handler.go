@@ -10,6 +10,8 @@ import (
 	"ipincamp/srikandi-sehat/src/models/menstrual"
 	"ipincamp/srikandi-sehat/src/models/region"
 	"ipincamp/srikandi-sehat/src/utils"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -183,12 +185,322 @@ func ChangeMyPassword(c *fiber.Ctx) error {
 		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to update password")
 	}
 
-	return utils.SendSuccess(c, fiber.StatusOK, "Password changed successfully", nil)
+	// Cabut semua sesi dan token, termasuk yang sedang dipakai, agar perangkat lain ikut keluar.
+	if err := utils.RevokeAllUserTokens(user.ID, utils.SessionRevokedPasswordChange); err != nil {
+		utils.ErrorLogger.Printf("Failed to revoke tokens after password change for %s: %v", userUUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Password changed, but failed to revoke active sessions")
+	}
+
+	utils.AuthLogger.Printf("Password changed, all sessions revoked: %s", userUUID)
+	return utils.SendSuccess(c, fiber.StatusOK, "Password changed successfully. Please log in again.", nil)
+}
+
+// RequestEmailChange mengirim OTP ke alamat email baru. Email akun baru diganti
+// setelah OTP dikonfirmasi lewat ConfirmEmailChange.
+func RequestEmailChange(c *fiber.Ctx) error {
+	userUUID := c.Locals("user_id").(string)
+	input := c.Locals("request_body").(*dto.ChangeEmailRequest)
+	newEmail := strings.TrimSpace(input.NewEmail)
+
+	var user models.User
+	if err := database.DB.First(&user, "uuid = ?", userUUID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.SendError(c, fiber.StatusNotFound, "User not found")
+		}
+		return utils.SendError(c, fiber.StatusInternalServerError, "Database error")
+	}
+
+	match, err := utils.CheckPasswordHash(input.Password, user.Password)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to verify password")
+	}
+	if !match {
+		utils.AuthLogger.Printf("Email change request failed (invalid password): %s", userUUID)
+		return utils.SendError(c, fiber.StatusUnauthorized, "Password is incorrect")
+	}
+
+	if strings.EqualFold(newEmail, user.Email) {
+		return utils.SendError(c, fiber.StatusUnprocessableEntity, "New email must be different from the current email")
+	}
+
+	if !isDomainAllowed(newEmail) {
+		utils.AuthLogger.Printf("Email change failed (domain not allowed): %s -> %s", userUUID, newEmail)
+		return utils.SendError(c, fiber.StatusUnprocessableEntity, "Email dari domain ini tidak diizinkan.")
+	}
+
+	// Unscoped: email milik akun yang di-soft delete tetap terkunci oleh unique index.
+	var count int64
+	if err := database.DB.Unscoped().Model(&models.User{}).Where("email = ?", newEmail).Count(&count).Error; err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Database error")
+	}
+	if count > 0 {
+		utils.AuthLogger.Printf("Email change failed (email exists): %s -> %s", userUUID, newEmail)
+		return utils.SendError(c, fiber.StatusConflict, "Email sudah terdaftar")
+	}
+
+	if errMsg := otpResendWaitMessage(user); errMsg != "" {
+		utils.AuthLogger.Printf("Email change failed (429 Too Many Requests): %s", userUUID)
+		return utils.SendError(c, fiber.StatusTooManyRequests, errMsg)
+	}
+
+	changeToken, err := utils.GenerateOTP(constants.OTPLength)
+	if err != nil {
+		utils.ErrorLogger.Printf("Gagal membuat OTP (ganti email): %v", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Gagal memproses permintaan")
+	}
+	hashedChangeToken, err := utils.HashOTP(changeToken)
+	if err != nil {
+		utils.ErrorLogger.Printf("Gagal hash OTP (ganti email) untuk %s: %v", userUUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Gagal memproses permintaan")
+	}
+	changeExpires := time.Now().Add(constants.OTPExpiryMinutes * time.Minute)
+
+	updates := map[string]interface{}{
+		"pending_email":           newEmail,
+		"email_change_token":      hashedChangeToken,
+		"email_change_expires_at": changeExpires,
+		"email_change_attempts":   0,
+		"last_otp_sent_at":        time.Now(),
+	}
+	if err := database.DB.Model(&user).Updates(updates).Error; err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Gagal memperbarui token")
+	}
+
+	if err := utils.SendEmailChangeOTPEmail(newEmail, changeToken, changeExpires); err != nil {
+		utils.ErrorLogger.Printf("Gagal mengirim OTP ganti email ke %s: %v", newEmail, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Gagal mengirim email verifikasi.")
+	}
+
+	utils.AuthLogger.Printf("Email change OTP sent: %s -> %s", userUUID, newEmail)
+	return utils.SendSuccess(c, fiber.StatusOK, "Kode OTP telah dikirim ke email baru Anda.", dto.EmailChangeResponse{
+		PendingEmail: newEmail,
+		ExpiresAt:    changeExpires,
+	})
+}
+
+// ConfirmEmailChange memverifikasi OTP dan mengganti email akun dengan alamat yang tertunda.
+// Alamat lama diberi tahu setelah penggantian berhasil.
+func ConfirmEmailChange(c *fiber.Ctx) error {
+	userUUID := c.Locals("user_id").(string)
+	input := c.Locals("request_body").(*dto.VerifyOTPRequest)
+
+	var user models.User
+	if err := database.DB.First(&user, "uuid = ?", userUUID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.SendError(c, fiber.StatusNotFound, "User not found")
+		}
+		return utils.SendError(c, fiber.StatusInternalServerError, "Database error")
+	}
+
+	clearEmailChange := map[string]interface{}{
+		"pending_email":           nil,
+		"email_change_token":      nil,
+		"email_change_expires_at": nil,
+		"email_change_attempts":   0,
+	}
+
+	if !user.PendingEmail.Valid || !user.EmailChangeToken.Valid || !user.EmailChangeExpiresAt.Valid {
+		utils.AuthLogger.Printf("Email change confirmation failed (no active OTP): %s", userUUID)
+		return utils.SendError(c, fiber.StatusForbidden, "Tidak ada permintaan perubahan email yang aktif.")
+	}
+
+	if time.Now().After(user.EmailChangeExpiresAt.Time) {
+		database.DB.Model(&user).Updates(clearEmailChange)
+		utils.AuthLogger.Printf("Email change confirmation failed (expired): %s", userUUID)
+		return utils.SendError(c, fiber.StatusForbidden, "Kode OTP telah kedaluwarsa. Silakan ajukan perubahan email lagi.")
+	}
+
+	allowed, err := utils.ConsumeOTPAttempt(user.ID, "email_change_token", user.EmailChangeToken.String, "email_change_attempts")
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to record email change attempt for %s: %v", userUUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Database error")
+	}
+	if !allowed {
+		if err := database.DB.Model(&user).Where("email_change_token = ?", user.EmailChangeToken.String).Updates(clearEmailChange).Error; err != nil {
+			utils.ErrorLogger.Printf("Failed to cancel email change OTP for %s: %v", userUUID, err)
+		}
+		utils.AuthLogger.Printf("Email change OTP locked out after %d failed attempts: %s", constants.OTPMaxAttempts, userUUID)
+		return utils.SendError(c, fiber.StatusTooManyRequests, "Terlalu banyak percobaan. Silakan ajukan perubahan email lagi.")
+	}
+
+	if !utils.CheckOTP(input.OTP, user.EmailChangeToken.String) {
+		utils.AuthLogger.Printf("Email change confirmation failed (wrong OTP): %s", userUUID)
+		return utils.SendError(c, fiber.StatusUnauthorized, "Kode OTP salah.")
+	}
+
+	oldEmail := user.Email
+	newEmail := user.PendingEmail.String
+	changedAt := time.Now()
+
+	// Email bisa saja sudah dipakai akun lain sejak OTP dikirim.
+	var count int64
+	if err := database.DB.Unscoped().Model(&models.User{}).Where("email = ? AND id <> ?", newEmail, user.ID).Count(&count).Error; err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Database error")
+	}
+	if count > 0 {
+		database.DB.Model(&user).Updates(clearEmailChange)
+		utils.AuthLogger.Printf("Email change confirmation failed (email taken meanwhile): %s -> %s", userUUID, newEmail)
+		return utils.SendError(c, fiber.StatusConflict, "Email sudah terdaftar")
+	}
+
+	updates := map[string]interface{}{
+		"email":             newEmail,
+		"email_verified_at": changedAt,
+	}
+	for column, value := range clearEmailChange {
+		updates[column] = value
+	}
+	if err := database.DB.Model(&user).Updates(updates).Error; err != nil {
+		utils.ErrorLogger.Printf("Failed to change email for %s: %v", userUUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to change email")
+	}
+
+	utils.AddEmailToRegistrationFilter(newEmail)
+
+	go func() {
+		if err := utils.SendEmailChangedNoticeEmail(oldEmail, newEmail, changedAt); err != nil {
+			utils.ErrorLogger.Printf("Failed to send email change notice to %s: %v", oldEmail, err)
+		}
+	}()
+
+	utils.AuthLogger.Printf("Email changed: %s (%s -> %s)", userUUID, oldEmail, newEmail)
+	return utils.SendSuccess(c, fiber.StatusOK, "Email berhasil diubah.", nil)
+}
+
+// CancelEmailChange membatalkan permintaan perubahan email yang belum dikonfirmasi.
+func CancelEmailChange(c *fiber.Ctx) error {
+	userUUID := c.Locals("user_id").(string)
+
+	result := database.DB.Model(&models.User{}).
+		Where("uuid = ? AND pending_email IS NOT NULL", userUUID).
+		Updates(map[string]interface{}{
+			"pending_email":           nil,
+			"email_change_token":      nil,
+			"email_change_expires_at": nil,
+			"email_change_attempts":   0,
+		})
+	if result.Error != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to cancel email change")
+	}
+	if result.RowsAffected == 0 {
+		return utils.SendError(c, fiber.StatusConflict, "No email change has been requested")
+	}
+
+	utils.AuthLogger.Printf("Email change cancelled: %s", userUUID)
+	return utils.SendSuccess(c, fiber.StatusOK, "Email change cancelled", nil)
+}
+
+// accountDeletionGracePeriod membaca masa tenggang penghapusan akun dari ACCOUNT_DELETION_GRACE_DAYS (default 14 hari).
+func accountDeletionGracePeriod() time.Duration {
+	days, err := strconv.Atoi(config.Get("ACCOUNT_DELETION_GRACE_DAYS"))
+	if err != nil || days < 0 {
+		days = 14
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// RequestAccountDeletion menjadwalkan penghapusan akun beserta seluruh data kesehatan user.
+// Data baru benar-benar dihapus oleh worker setelah masa tenggang berakhir.
+func RequestAccountDeletion(c *fiber.Ctx) error {
+	userUUID := c.Locals("user_id").(string)
+	input := c.Locals("request_body").(*dto.DeleteAccountRequest)
+
+	var user models.User
+	if err := database.DB.First(&user, "uuid = ?", userUUID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.SendError(c, fiber.StatusNotFound, "User not found")
+		}
+		return utils.SendError(c, fiber.StatusInternalServerError, "Database error")
+	}
+
+	if user.DeletionScheduledAt.Valid {
+		return utils.SendError(c, fiber.StatusConflict, "Account deletion has already been requested")
+	}
+
+	match, err := utils.CheckPasswordHash(input.Password, user.Password)
+	if err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to verify password")
+	}
+	if !match {
+		utils.AuthLogger.Printf("Account deletion request failed (invalid password): %s", userUUID)
+		return utils.SendError(c, fiber.StatusUnauthorized, "Password is incorrect")
+	}
+
+	requestedAt := time.Now()
+	scheduledAt := requestedAt.Add(accountDeletionGracePeriod())
+	updates := map[string]interface{}{
+		"deletion_requested_at": requestedAt,
+		"deletion_scheduled_at": scheduledAt,
+	}
+	if err := database.DB.Model(&user).Updates(updates).Error; err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to request account deletion")
+	}
+
+	go func(email string) {
+		if err := utils.SendAccountDeletionScheduledEmail(email, scheduledAt); err != nil {
+			utils.ErrorLogger.Printf("Failed to send account deletion email to %s: %v", email, err)
+		}
+	}(user.Email)
+
+	utils.AuthLogger.Printf("Account deletion requested: %s (scheduled at %s)", userUUID, scheduledAt.Format(time.RFC3339))
+	return utils.SendSuccess(c, fiber.StatusOK, "Account deletion scheduled. You can cancel it before the scheduled time.", dto.AccountDeletionResponse{
+		RequestedAt: requestedAt,
+		ScheduledAt: scheduledAt,
+	})
+}
+
+// CancelAccountDeletion membatalkan permintaan penghapusan akun selama masa tenggang.
+func CancelAccountDeletion(c *fiber.Ctx) error {
+	userUUID := c.Locals("user_id").(string)
+
+	var user models.User
+	if err := database.DB.First(&user, "uuid = ?", userUUID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.SendError(c, fiber.StatusNotFound, "User not found")
+		}
+		return utils.SendError(c, fiber.StatusInternalServerError, "Database error")
+	}
+
+	if !user.DeletionScheduledAt.Valid {
+		return utils.SendError(c, fiber.StatusConflict, "No account deletion has been requested")
+	}
+
+	updates := map[string]interface{}{
+		"deletion_requested_at": nil,
+		"deletion_scheduled_at": nil,
+	}
+	if err := database.DB.Model(&user).Updates(updates).Error; err != nil {
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to cancel account deletion")
+	}
+
+	utils.AuthLogger.Printf("Account deletion cancelled: %s", userUUID)
+	return utils.SendSuccess(c, fiber.StatusOK, "Account deletion cancelled", nil)
+}
+
+// applyAdminRegionScope membatasi query user ke wilayah admin yang sedang login (jika berwilayah).
+// column adalah kolom id user pada query, misalnya "users.id" atau "user_id".
+func applyAdminRegionScope(c *fiber.Ctx, column string) (func(*gorm.DB) *gorm.DB, error) {
+	regionCodes, restricted, err := utils.GetUserRegionScope(c.Locals("user_id").(string))
+	if err != nil {
+		return nil, err
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		if !restricted {
+			return db
+		}
+		return db.Where(column+" IN (?)", utils.UserIDsInRegions(regionCodes))
+	}, nil
 }
 
 func GetAllUsers(c *fiber.Ctx) error {
 	queries := c.Locals("request_queries").(*dto.UserQuery)
 
+	regionScope, err := applyAdminRegionScope(c, "users.id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
 	var users []models.User
 
 	subQuery := database.DB.Table("user_roles").
@@ -198,7 +510,7 @@ func GetAllUsers(c *fiber.Ctx) error {
 
 	query := database.DB.Model(&models.User{})
 
-	query = query.Where("id NOT IN (?)", subQuery)
+	query = query.Where("id NOT IN (?)", subQuery).Scopes(regionScope)
 
 	if queries.Classification != "" {
 		query = query.
@@ -249,9 +561,15 @@ func GetUserByID(c *fiber.Ctx) error {
 	params := c.Locals("request_params").(*dto.UserParam)
 	userUUID := params.ID
 
-	// 1. Fetch user with profile details
+	regionScope, err := applyAdminRegionScope(c, "users.id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	// 1. Fetch user with profile details (user di luar wilayah petugas dianggap tidak ada)
 	var user models.User
 	result := database.DB.
+		Scopes(regionScope).
 		Preload("Roles").
 		Preload("Profile.Village.Classification").
 		Preload("Profile.Village.District.Regency.Province").
@@ -312,6 +630,11 @@ func GetUserByID(c *fiber.Ctx) error {
 }
 
 func GetUserStatistics(c *fiber.Ctx) error {
+	regionScope, err := applyAdminRegionScope(c, "users.id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
 	var stats dto.UserStatisticsResponse
 	var wg sync.WaitGroup
 	var dbErr error
@@ -325,6 +648,7 @@ func GetUserStatistics(c *fiber.Ctx) error {
 		defer wg.Done()
 		var count int64
 		err := database.DB.Model(&models.User{}).
+			Scopes(regionScope).
 			Joins("JOIN profiles ON users.id = profiles.user_id").
 			Joins("JOIN villages ON profiles.village_id = villages.id").
 			Joins("JOIN classifications ON villages.classification_id = classifications.id").
@@ -343,6 +667,7 @@ func GetUserStatistics(c *fiber.Ctx) error {
 		defer wg.Done()
 		var count int64
 		err := database.DB.Model(&models.User{}).
+			Scopes(regionScope).
 			Joins("JOIN profiles ON users.id = profiles.user_id").
 			Joins("JOIN villages ON profiles.village_id = villages.id").
 			Joins("JOIN classifications ON villages.classification_id = classifications.id").
@@ -362,6 +687,7 @@ func GetUserStatistics(c *fiber.Ctx) error {
 		defer wg.Done()
 		var count int64
 		err := database.DB.Table("users").
+			Scopes(regionScope).
 			Joins("JOIN (SELECT user_id, COUNT(id) as cycle_count FROM menstrual_cycles GROUP BY user_id) as mc ON users.id = mc.user_id").
 			Where("mc.cycle_count >= 2").
 			Count(&count).Error
@@ -384,6 +710,7 @@ func GetUserStatistics(c *fiber.Ctx) error {
 			Where("roles.name = ?", string(constants.AdminRole))
 
 		err := database.DB.Model(&models.User{}).
+			Scopes(regionScope).
 			Where("id NOT IN (?)", subQuery).
 			Count(&count).Error
 		if err != nil {
@@ -413,6 +740,76 @@ func GetUserStatistics(c *fiber.Ctx) error {
 	return utils.SendSuccess(c, fiber.StatusOK, "User statistics fetched successfully", stats)
 }
 
+// GetPasswordHashReport menghitung jumlah user per versi kebijakan hash password,
+// termasuk user yang masih memakai parameter atau algoritma lama.
+func GetPasswordHashReport(c *fiber.Ctx) error {
+	regionScope, err := applyAdminRegionScope(c, "users.id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	current := utils.CurrentPasswordHashPolicy()
+	report := dto.PasswordHashReportResponse{CurrentVersion: current.Version, Groups: []dto.PasswordHashGroup{}}
+	groupIndex := make(map[utils.PasswordHashInfo]int)
+
+	var users []models.User
+	err = database.DB.Scopes(regionScope).Select("id", "password").FindInBatches(&users, 500, func(tx *gorm.DB, batch int) error {
+		for _, user := range users {
+			info := utils.InspectPasswordHash(user.Password)
+			index, exists := groupIndex[info]
+			if !exists {
+				index = len(report.Groups)
+				groupIndex[info] = index
+				report.Groups = append(report.Groups, dto.PasswordHashGroup{
+					Version:   info.Version,
+					Algorithm: info.Algorithm,
+					Params:    info.Params,
+					IsCurrent: info.Version == current.Version,
+				})
+			}
+			report.Groups[index].Users++
+			report.TotalUsers++
+			if info.Version != current.Version {
+				report.OutdatedUsers++
+			}
+		}
+		return nil
+	}).Error
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to build password hash report: %v", err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to build password hash report")
+	}
+
+	return utils.SendSuccess(c, fiber.StatusOK, "Password hash report fetched successfully", report)
+}
+
+// UnlockUserAccount membuka kunci login akun yang terkunci karena percobaan login gagal.
+func UnlockUserAccount(c *fiber.Ctx) error {
+	params := c.Locals("request_params").(*dto.UserParam)
+	adminUUID := c.Locals("user_id").(string)
+
+	regionScope, err := applyAdminRegionScope(c, "users.id")
+	if err != nil {
+		return utils.SendError(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	var user models.User
+	if err := database.DB.Scopes(regionScope).Select("id", "uuid").First(&user, "uuid = ?", params.ID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.SendError(c, fiber.StatusNotFound, "User not found")
+		}
+		return utils.SendError(c, fiber.StatusInternalServerError, "Database error")
+	}
+
+	if err := utils.ResetLoginFailures(models.LoginLockoutScopeAccount, user.UUID); err != nil {
+		utils.ErrorLogger.Printf("Failed to unlock account %s: %v", user.UUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to unlock account")
+	}
+
+	utils.AuthLogger.Printf("Account unlocked by admin %s: %s", adminUUID, user.UUID)
+	return utils.SendSuccess(c, fiber.StatusOK, "Account unlocked successfully", nil)
+}
+
 func UpdateFcmToken(c *fiber.Ctx) error {
 	userUUID := c.Locals("user_id").(string)
 
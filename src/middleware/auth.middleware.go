@@ -1,10 +1,8 @@
 package middleware
 
 import (
-	"fmt"
 	"strings"
 
-	"ipincamp/srikandi-sehat/config"
 	"ipincamp/srikandi-sehat/src/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -22,17 +20,7 @@ func AuthMiddleware(c *fiber.Ctx) error {
 		return utils.SendError(c, fiber.StatusUnauthorized, "Invalid token format, 'Bearer ' prefix missing")
 	}
 
-	// if utils.IsTokenBlocked(tokenString) {
-	// 	return utils.SendError(c, fiber.StatusUnauthorized, "Token has been invalidated")
-	// }
-
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(config.Get("JWT_SECRET")), nil
-	})
-
+	token, err := utils.ParseJWT(tokenString)
 	if err != nil || !token.Valid {
 		return utils.SendError(c, fiber.StatusUnauthorized, "Invalid or expired token")
 	}
@@ -42,11 +30,28 @@ func AuthMiddleware(c *fiber.Ctx) error {
 		return utils.SendError(c, fiber.StatusUnauthorized, "Invalid token claims")
 	}
 
+	// Token tantangan 2FA tidak boleh dipakai sebagai access token.
+	if claims["typ"] == utils.TwoFactorChallengeType {
+		return utils.SendError(c, fiber.StatusUnauthorized, "Invalid token type")
+	}
+
+	jti, ok := claims["jti"].(string)
+	if !ok || jti == "" {
+		return utils.SendError(c, fiber.StatusUnauthorized, "Invalid token claims")
+	}
+
+	if utils.IsTokenBlocked(jti) {
+		return utils.SendError(c, fiber.StatusUnauthorized, "Token has been invalidated")
+	}
+
 	userUUID, ok := claims["usr"].(string)
 	if !ok {
 		return utils.SendError(c, fiber.StatusUnauthorized, "Invalid user identifier in token")
 	}
 
 	c.Locals("user_id", userUUID)
+	if sessionUUID, ok := claims["sid"].(string); ok {
+		c.Locals("session_id", sessionUUID)
+	}
 	return c.Next()
 }
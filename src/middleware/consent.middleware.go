@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"ipincamp/srikandi-sehat/src/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ConsentMiddleware memblokir akses ke data kesehatan sampai persetujuan untuk versi naskah
+// yang berlaku tercatat (oleh user sendiri, atau oleh wali untuk user di bawah umur).
+func ConsentMiddleware(c *fiber.Ctx) error {
+	userUUID, ok := c.Locals("user_id").(string)
+	if !ok {
+		return utils.SendError(c, fiber.StatusUnauthorized, "Unauthorized")
+	}
+
+	granted, err := utils.HasActiveConsent(userUUID)
+	if err != nil {
+		utils.ErrorLogger.Printf("Failed to check consent for %s: %v", userUUID, err)
+		return utils.SendError(c, fiber.StatusInternalServerError, "Failed to check consent status")
+	}
+
+	if !granted {
+		return utils.SendError(c, fiber.StatusForbidden, "Consent is required before you can access this resource. Please complete the consent process (guardian consent is required for minors).")
+	}
+
+	return c.Next()
+}
@@ -19,16 +19,16 @@ func MaintenanceMiddleware() fiber.Handler {
 
 		// --- Maintenance sedang Aktif ---
 
-		// 1. Selalu izinkan akses ke health check
-		if c.Path() == "/api/health" {
+		// 1. Selalu izinkan akses ke health check dan JWKS (dipakai partner untuk verifikasi token)
+		if c.Path() == "/api/health" || c.Path() == "/.well-known/jwks.json" {
 			return c.Next()
 		}
 
 		// 2. Selalu izinkan request ke endpoint manajemen maintenance untuk diproses lebih lanjut.
-		//    Middleware AuthMiddleware dan AdminMiddleware (yang didefinisikan di grup route)
-		//    akan menangani otorisasi apakah user adalah admin atau bukan.
+		//    Middleware AuthMiddleware dan PermissionMiddleware (yang didefinisikan di grup route)
+		//    akan menangani otorisasi apakah user boleh mengelola maintenance atau tidak.
 		if strings.HasPrefix(c.Path(), "/api/admin/maintenance") {
-			return c.Next() // Lanjutkan ke middleware berikutnya (Auth, Admin)
+			return c.Next() // Lanjutkan ke middleware berikutnya (Auth, Permission)
 		}
 
 		// 3. Untuk SEMUA path LAINNYA, periksa apakah user terautentikasi dan ada di whitelist
@@ -1,38 +1,24 @@
 package middleware
 
 import (
-	"ipincamp/srikandi-sehat/database"
-	"ipincamp/srikandi-sehat/src/models"
+	"ipincamp/srikandi-sehat/src/constants"
 	"ipincamp/srikandi-sehat/src/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func UserHasPermission(userUUID string, requiredPermission string) bool {
-	var user models.User
-	err := database.DB.Preload("Roles.Permissions").Preload("Permissions").First(&user, "uuid = ?", userUUID).Error
+// UserHasPermission memeriksa permission efektif user lewat cache di utils.GetUserPermissions.
+func UserHasPermission(userUUID string, requiredPermission constants.PermissionName) bool {
+	permissions, err := utils.GetUserPermissions(userUUID)
 	if err != nil {
 		return false
 	}
 
-	for _, p := range user.Permissions {
-		if p.Name == requiredPermission {
-			return true
-		}
-	}
-
-	for _, r := range user.Roles {
-		for _, p := range r.Permissions {
-			if p.Name == requiredPermission {
-				return true
-			}
-		}
-	}
-
-	return false
+	_, ok := permissions[string(requiredPermission)]
+	return ok
 }
 
-func PermissionMiddleware(requiredPermission string) fiber.Handler {
+func PermissionMiddleware(requiredPermission constants.PermissionName) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		userUUID, ok := c.Locals("user_id").(string)
 		if !ok {
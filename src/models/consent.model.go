@@ -0,0 +1,39 @@
+package models
+
+import (
+	"database/sql"
+	"ipincamp/srikandi-sehat/src/constants"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Consent mencatat persetujuan pengumpulan data kesehatan untuk satu versi naskah.
+// Untuk user di bawah umur, persetujuan berstatus tertunda sampai wali mengonfirmasi
+// lewat tautan email (hanya hash token yang disimpan).
+type Consent struct {
+	ID             uint                  `gorm:"primarykey"`
+	UUID           string                `gorm:"type:char(36);uniqueIndex;not null"`
+	UserID         uint                  `gorm:"not null;index"`
+	User           User                  `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Version        string                `gorm:"type:varchar(20);not null;index"`
+	Type           constants.ConsentType `gorm:"type:enum('self','guardian');not null"`
+	GuardianName   sql.NullString        `gorm:"type:varchar(100)"`
+	GuardianEmail  sql.NullString        `gorm:"type:varchar(255)"`
+	TokenHash      sql.NullString        `gorm:"type:char(64);uniqueIndex"`
+	TokenExpiresAt sql.NullTime          `gorm:"column:token_expires_at"`
+	GrantedAt      sql.NullTime          `gorm:"column:granted_at"`
+	GrantedIP      sql.NullString        `gorm:"column:granted_ip;type:varchar(45)"`
+	RevokedAt      sql.NullTime          `gorm:"column:revoked_at"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (consent *Consent) BeforeCreate(tx *gorm.DB) (err error) {
+	if consent.UUID == "" {
+		consent.UUID = uuid.New().String()
+	}
+	return
+}
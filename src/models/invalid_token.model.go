@@ -4,6 +4,6 @@ import "time"
 
 type InvalidToken struct {
 	ID        uint      `gorm:"primarykey"`
-	Token     string    `gorm:"type:text;uniqueIndex;not null"`
-	ExpiresAt time.Time `gorm:"not null"`
+	JTI       string    `gorm:"column:jti;type:char(36);uniqueIndex;not null"`
+	ExpiresAt time.Time `gorm:"not null;index"`
 }
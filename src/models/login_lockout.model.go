@@ -0,0 +1,30 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// Scope LoginLockout: percobaan gagal dihitung per akun (Identifier = UUID user),
+// per alamat IP (Identifier = IP), dan per token tantangan 2FA (Identifier = jti tantangan).
+const (
+	LoginLockoutScopeAccount            = "account"
+	LoginLockoutScopeIP                 = "ip"
+	LoginLockoutScopeTwoFactorChallenge = "2fa"
+)
+
+// LoginLockout menyimpan jumlah login gagal dan status kunci di database agar
+// tetap berlaku setelah restart dan dibagi antar instance.
+// LockoutCount dipakai untuk memperpanjang durasi kunci berikutnya.
+type LoginLockout struct {
+	ID             uint         `gorm:"primarykey"`
+	Scope          string       `gorm:"type:varchar(10);not null;uniqueIndex:idx_login_lockouts_scope_identifier"`
+	Identifier     string       `gorm:"type:varchar(64);not null;uniqueIndex:idx_login_lockouts_scope_identifier"`
+	FailedAttempts uint         `gorm:"not null;default:0"`
+	LockoutCount   uint         `gorm:"not null;default:0"`
+	LockedUntil    sql.NullTime `gorm:"column:locked_until"`
+	LastFailedAt   time.Time    `gorm:"not null;index"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
@@ -0,0 +1,23 @@
+package menstrual
+
+import (
+	"database/sql"
+	"ipincamp/srikandi-sehat/src/constants"
+	"time"
+)
+
+// FlowLog mencatat intensitas aliran darah per hari. Entri di luar siklus mana pun
+// (MenstrualCycleID NULL) ditandai sebagai perdarahan intermenstrual.
+type FlowLog struct {
+	ID               uint                    `gorm:"primarykey"`
+	Date             time.Time               `gorm:"type:date;not null;uniqueIndex:idx_flow_logs_user_date"`
+	Intensity        constants.FlowIntensity `gorm:"type:enum('spotting','light','medium','heavy','clots');not null"`
+	IsIntermenstrual bool                    `gorm:"not null;default:false"`
+	Note             string                  `gorm:"type:varchar(255)"`
+
+	UserID           uint `gorm:"not null;uniqueIndex:idx_flow_logs_user_date"`
+	MenstrualCycleID sql.NullInt64
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
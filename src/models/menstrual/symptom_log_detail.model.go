@@ -11,4 +11,5 @@ type SymptomLogDetail struct {
 	Symptom         Symptom    `gorm:"foreignKey:SymptomID"`
 	SymptomOptionID sql.NullInt64
 	SymptomOption   SymptomOption `gorm:"foreignKey:SymptomOptionID"`
+	Severity        sql.NullInt16 `gorm:"type:tinyint unsigned"` // Skala 0–10, NULL jika tidak diisi
 }
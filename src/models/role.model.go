@@ -1,11 +1,18 @@
 package models
 
-import "time"
+import (
+	"database/sql"
+	"time"
+)
 
 type Role struct {
 	ID   uint   `gorm:"primarykey"`
 	Name string `gorm:"type:varchar(100);uniqueIndex;not null"`
 
+	// RegionCode membatasi akses admin pemegang role ke user di wilayah tersebut
+	// (kode provinsi, kabupaten/kota, kecamatan, atau desa). NULL berarti tanpa batas wilayah.
+	RegionCode sql.NullString `gorm:"type:varchar(10);index"`
+
 	Permissions []*Permission `gorm:"many2many:role_permissions;"`
 
 	CreatedAt time.Time `gorm:"autoCreateTime"`
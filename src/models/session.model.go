@@ -0,0 +1,39 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Session merepresentasikan satu login (satu "family" refresh token).
+// Hanya hash dari refresh token terakhir yang disimpan; setiap rotasi menggantinya.
+// AccessTokenJTI menyimpan jti access token terakhir agar bisa dicabut bersama sesinya.
+type Session struct {
+	ID                   uint           `gorm:"primarykey"`
+	UUID                 string         `gorm:"type:char(36);uniqueIndex;not null"`
+	UserID               uint           `gorm:"not null;index"`
+	User                 User           `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	RefreshTokenHash     string         `gorm:"type:char(64);not null"`
+	AccessTokenJTI       string         `gorm:"column:access_token_jti;type:char(36)"`
+	AccessTokenExpiresAt sql.NullTime   `gorm:"column:access_token_expires_at"`
+	DeviceName           string         `gorm:"type:varchar(100)"`
+	UserAgent            string         `gorm:"type:varchar(255)"`
+	IPAddress            string         `gorm:"type:varchar(45)"`
+	LastUsedAt           time.Time      `gorm:"not null"`
+	ExpiresAt            time.Time      `gorm:"not null;index"`
+	RevokedAt            sql.NullTime   `gorm:"index"`
+	RevokedReason        sql.NullString `gorm:"type:varchar(50)"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (session *Session) BeforeCreate(tx *gorm.DB) (err error) {
+	if session.UUID == "" {
+		session.UUID = uuid.New().String()
+	}
+	return
+}
@@ -0,0 +1,15 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type TwoFactorRecoveryCode struct {
+	ID        uint         `gorm:"primarykey"`
+	UserID    uint         `gorm:"not null;index"`
+	User      User         `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	CodeHash  string       `gorm:"type:char(64);not null"`
+	UsedAt    sql.NullTime `gorm:"column:used_at"`
+	CreatedAt time.Time    `gorm:"autoCreateTime"`
+}
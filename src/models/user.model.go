@@ -19,8 +19,25 @@ type User struct {
 	EmailVerifiedAt       sql.NullTime   `gorm:"column:email_verified_at"`
 	VerificationToken     sql.NullString `gorm:"column:verification_token"`
 	VerificationExpiresAt sql.NullTime   `gorm:"column:verification_expires_at"`
+	VerificationAttempts  uint           `gorm:"column:verification_attempts;default:0"`
 	LastOTPSentAt         sql.NullTime   `gorm:"column:last_otp_sent_at"`
 
+	PasswordResetToken     sql.NullString `gorm:"column:password_reset_token"`
+	PasswordResetExpiresAt sql.NullTime   `gorm:"column:password_reset_expires_at"`
+	PasswordResetAttempts  uint           `gorm:"column:password_reset_attempts;default:0"`
+
+	PendingEmail         sql.NullString `gorm:"column:pending_email"`
+	EmailChangeToken     sql.NullString `gorm:"column:email_change_token"`
+	EmailChangeExpiresAt sql.NullTime   `gorm:"column:email_change_expires_at"`
+	EmailChangeAttempts  uint           `gorm:"column:email_change_attempts;default:0"`
+
+	TwoFactorSecret    sql.NullString `gorm:"column:two_factor_secret"` // Terenkripsi (AES-GCM)
+	TwoFactorEnabledAt sql.NullTime   `gorm:"column:two_factor_enabled_at"`
+	TwoFactorLastStep  int64          `gorm:"column:two_factor_last_step;default:0"`
+
+	DeletionRequestedAt sql.NullTime `gorm:"column:deletion_requested_at"`
+	DeletionScheduledAt sql.NullTime `gorm:"column:deletion_scheduled_at;index"`
+
 	Roles       []*Role       `gorm:"many2many:user_roles;"`
 	Permissions []*Permission `gorm:"many2many:user_permissions;"`
 	Profile     Profile       `gorm:"foreignKey:UserID"`
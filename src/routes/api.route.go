@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"ipincamp/srikandi-sehat/src/constants"
 	"ipincamp/srikandi-sehat/src/dto"
 	"ipincamp/srikandi-sehat/src/handlers"
 	menstrualHandler "ipincamp/srikandi-sehat/src/handlers/menstrual"
@@ -12,12 +13,16 @@ import (
 
 func SetupRoutes(app *fiber.App) {
 	app.Get("/api/health", handlers.HealthCheck)
+	app.Get("/.well-known/jwks.json", handlers.GetJWKS)
 	api := app.Group("/api")
 
 	// Auth routes
 	auth := api.Group("/auth")
 	registerLimiter := middleware.IPRateLimiter(50, 5*time.Minute)
 	loginLimiter := middleware.LoginRateLimiter(5, 1*time.Minute)
+	refreshLimiter := middleware.IPRateLimiter(30, 1*time.Minute)
+	passwordResetLimiter := middleware.IPRateLimiter(10, 15*time.Minute)
+	twoFactorLimiter := middleware.IPRateLimiter(10, 1*time.Minute)
 	auth.Post("/register",
 		registerLimiter,
 		middleware.ValidateBody[dto.RegisterRequest],
@@ -28,6 +33,31 @@ func SetupRoutes(app *fiber.App) {
 		middleware.ValidateBody[dto.LoginRequest],
 		handlers.Login,
 	)
+	auth.Post("/2fa/setup",
+		twoFactorLimiter,
+		middleware.ValidateBody[dto.TwoFactorChallengeRequest],
+		handlers.SetupTwoFactorChallenge,
+	)
+	auth.Post("/2fa/verify",
+		twoFactorLimiter,
+		middleware.ValidateBody[dto.TwoFactorVerifyRequest],
+		handlers.VerifyTwoFactorChallenge,
+	)
+	auth.Post("/forgot-password",
+		passwordResetLimiter,
+		middleware.ValidateBody[dto.ForgotPasswordRequest],
+		handlers.ForgotPassword,
+	)
+	auth.Post("/reset-password",
+		passwordResetLimiter,
+		middleware.ValidateBody[dto.ResetPasswordRequest],
+		handlers.ResetPassword,
+	)
+	auth.Post("/refresh",
+		refreshLimiter,
+		middleware.ValidateBody[dto.RefreshTokenRequest],
+		handlers.RefreshToken,
+	)
 	auth.Post("/logout", middleware.AuthMiddleware, handlers.Logout)
 	auth.Post(
 		"/verify-otp",
@@ -46,7 +76,27 @@ func SetupRoutes(app *fiber.App) {
 	user.Get("/", handlers.GetMyProfile)
 	user.Put("/details", middleware.ValidateBody[dto.UpdateProfileRequest], handlers.UpdateOrCreateProfile)
 	user.Patch("/password", middleware.ValidateBody[dto.ChangePasswordRequest], handlers.ChangeMyPassword)
+	user.Post("/email", middleware.ValidateBody[dto.ChangeEmailRequest], handlers.RequestEmailChange)
+	user.Post("/email/verify", middleware.ValidateBody[dto.VerifyOTPRequest], handlers.ConfirmEmailChange)
+	user.Delete("/email", handlers.CancelEmailChange)
 	user.Patch("/fcm-token", handlers.UpdateFcmToken)
+	user.Post("/2fa/setup", handlers.SetupMyTwoFactor)
+	user.Post("/2fa/enable", middleware.ValidateBody[dto.TwoFactorCodeRequest], handlers.EnableMyTwoFactor)
+	user.Post("/2fa/disable", middleware.ValidateBody[dto.DisableTwoFactorRequest], handlers.DisableMyTwoFactor)
+	user.Post("/2fa/recovery-codes", middleware.ValidateBody[dto.TwoFactorCodeRequest], handlers.RegenerateMyRecoveryCodes)
+	user.Post("/deletion", middleware.ValidateBody[dto.DeleteAccountRequest], handlers.RequestAccountDeletion)
+	user.Delete("/deletion", handlers.CancelAccountDeletion)
+	user.Get("/sessions", handlers.GetMySessions)
+	user.Delete("/sessions", handlers.RevokeOtherSessions)
+	user.Delete("/sessions/:id", middleware.ValidateParams[dto.SessionParam], handlers.RevokeMySession)
+	user.Get("/consent", handlers.GetMyConsent)
+	user.Post("/consent",
+		middleware.UserRateLimiter(5, 15*time.Minute),
+		middleware.ValidateBody[dto.GiveConsentRequest],
+		handlers.GiveConsent,
+	)
+	user.Delete("/consent", handlers.WithdrawMyConsent)
+	user.Post("/export", middleware.UserRateLimiter(3, 1*time.Hour), handlers.RequestPersonalDataExport)
 	user.Post("/test-notification",
 		middleware.ValidateBody[dto.TestNotificationRequest], // Validasi request body
 		handlers.SendTestNotification,                        // Panggil handler baru
@@ -54,14 +104,36 @@ func SetupRoutes(app *fiber.App) {
 
 	// Admin routes
 	adminLimiter := middleware.UserRateLimiter(100, 1*time.Minute)
-	admin := api.Group("/admin", middleware.AuthMiddleware, middleware.AdminMiddleware, adminLimiter)
-	admin.Get("/users/statistics", handlers.GetUserStatistics)
-	admin.Post("/reports/generate-csv-link", handlers.GenerateFullReportLink)
-	admin.Get("/users", middleware.ValidateQuery[dto.UserQuery], handlers.GetAllUsers)
-	admin.Get("/users/:id", middleware.ValidateParams[dto.UserParam], handlers.GetUserByID)
-
-	// Maintenance Management Routes (Admin only)
-	maintenance := admin.Group("/maintenance")
+	admin := api.Group("/admin", middleware.AuthMiddleware, adminLimiter)
+	usersRead := middleware.PermissionMiddleware(constants.UsersReadPermission)
+	usersManage := middleware.PermissionMiddleware(constants.UsersManagePermission)
+	rolesManage := middleware.PermissionMiddleware(constants.RolesManagePermission)
+	reportsExport := middleware.PermissionMiddleware(constants.ReportsExportPermission)
+
+	admin.Get("/users/statistics", usersRead, handlers.GetUserStatistics)
+	admin.Get("/users/password-hash-report", usersRead, handlers.GetPasswordHashReport)
+	admin.Post("/reports/generate-csv-link", reportsExport, handlers.GenerateFullReportLink)
+	admin.Get("/users", usersRead, middleware.ValidateQuery[dto.UserQuery], handlers.GetAllUsers)
+	admin.Get("/users/:id", usersRead, middleware.ValidateParams[dto.UserParam], handlers.GetUserByID)
+	admin.Post("/users/:id/unlock", usersManage, middleware.ValidateParams[dto.UserParam], handlers.UnlockUserAccount)
+	admin.Get("/users/:id/access", usersRead, middleware.ValidateParams[dto.UserParam], handlers.GetUserAccess)
+	admin.Post("/users/:id/roles", rolesManage, middleware.ValidateParams[dto.UserParam], middleware.ValidateBody[dto.AssignRoleRequest], handlers.AssignRoleToUser)
+	admin.Delete("/users/:id/roles/:role", rolesManage, middleware.ValidateParams[dto.UserRoleParam], handlers.RevokeRoleFromUser)
+	admin.Post("/users/:id/permissions", rolesManage, middleware.ValidateParams[dto.UserParam], middleware.ValidateBody[dto.AssignPermissionRequest], handlers.GrantPermissionToUser)
+	admin.Delete("/users/:id/permissions/:permission", rolesManage, middleware.ValidateParams[dto.UserPermissionParam], handlers.RevokePermissionFromUser)
+
+	// Role & Permission Management Routes
+	admin.Get("/roles", rolesManage, handlers.GetAllRoles)
+	admin.Post("/roles", rolesManage, middleware.ValidateBody[dto.CreateRoleRequest], handlers.CreateRole)
+	admin.Put("/roles/:id", rolesManage, middleware.ValidateParams[dto.RoleParam], middleware.ValidateBody[dto.UpdateRoleRequest], handlers.UpdateRole)
+	admin.Delete("/roles/:id", rolesManage, middleware.ValidateParams[dto.RoleParam], handlers.DeleteRole)
+	admin.Get("/permissions", rolesManage, handlers.GetAllPermissions)
+	admin.Post("/permissions", rolesManage, middleware.ValidateBody[dto.PermissionRequest], handlers.CreatePermission)
+	admin.Put("/permissions/:id", rolesManage, middleware.ValidateParams[dto.PermissionParam], middleware.ValidateBody[dto.PermissionRequest], handlers.UpdatePermission)
+	admin.Delete("/permissions/:id", rolesManage, middleware.ValidateParams[dto.PermissionParam], handlers.DeletePermission)
+
+	// Maintenance Management Routes
+	maintenance := admin.Group("/maintenance", middleware.PermissionMiddleware(constants.MaintenanceManagePermission))
 	maintenance.Get("/", handlers.GetMaintenanceStatus)
 	maintenance.Post("/toggle", middleware.ValidateBody[dto.ToggleMaintenanceRequest], handlers.ToggleMaintenanceMode)
 	maintenance.Get("/whitelist", handlers.GetWhitelistedUsers)
@@ -81,19 +153,40 @@ func SetupRoutes(app *fiber.App) {
 
 	// Rute Unduhan Laporan
 	api.Get("/reports/download/:token", handlers.DownloadFullReportByToken)
+	api.Get("/exports/download/:token", handlers.DownloadPersonalDataExport)
+
+	// Rute persetujuan wali (dibuka dari tautan email, tanpa login)
+	guardianConsentLimiter := middleware.IPRateLimiter(20, 15*time.Minute)
+	api.Get("/consents/guardian/:token", guardianConsentLimiter, middleware.ValidateParams[dto.GuardianConsentParam], handlers.ShowGuardianConsent)
+	api.Post("/consents/guardian/:token", guardianConsentLimiter, middleware.ValidateParams[dto.GuardianConsentParam], handlers.ConfirmGuardianConsent)
 
 	// Menstrual health routes
-	menstrual := api.Group("/menstrual", middleware.AuthMiddleware, middleware.VerifiedMiddleware)
+	menstrual := api.Group("/menstrual", middleware.AuthMiddleware, middleware.VerifiedMiddleware, middleware.ConsentMiddleware)
 	menstrual.Get("/cycles/status", menstrualHandler.GetCycleStatus)
+	menstrual.Get("/cycles/analytics", middleware.ValidateQuery[dto.CycleAnalyticsQuery], menstrualHandler.GetCycleAnalytics)
+	menstrual.Get("/calendar", middleware.ValidateQuery[dto.CalendarQuery], menstrualHandler.GetCalendar)
 	menstrual.Post("/cycles", middleware.ValidateBody[dto.CycleRequest], menstrualHandler.RecordCycle)
+	menstrual.Post("/cycles/import", middleware.ValidateBody[dto.ImportCyclesRequest], menstrualHandler.ImportCycles)
 	menstrual.Get("/cycles", middleware.ValidateQuery[dto.PaginationQuery], menstrualHandler.GetCycleHistory)
 	menstrual.Get("/cycles/:id", middleware.ValidateParams[dto.CycleParam], menstrualHandler.GetCycleByID)
+	menstrual.Patch(
+		"/cycles/:id",
+		middleware.ValidateParams[dto.CycleParam],
+		middleware.ValidateBody[dto.UpdateCycleRequest],
+		menstrualHandler.UpdateCycleByID,
+	)
 	menstrual.Delete(
 		"/cycles/:id",
 		middleware.ValidateParams[dto.CycleParam],
 		middleware.ValidateBody[dto.DeleteCycleRequest],
 		menstrualHandler.DeleteCycleByID,
 	)
+	menstrual.Post("/cycles/:id/restore", middleware.ValidateParams[dto.CycleParam], menstrualHandler.RestoreCycleByID)
+
+	// Flow specific routes
+	menstrual.Post("/flow", middleware.ValidateBody[dto.FlowLogRequest], menstrualHandler.LogFlow)
+	menstrual.Get("/flow", middleware.ValidateQuery[dto.FlowLogQuery], menstrualHandler.GetFlowLogs)
+	menstrual.Delete("/flow/:id", middleware.ValidateParams[dto.FlowLogParam], menstrualHandler.DeleteFlowLog)
 
 	// Symptom specific routes
 	menstrual.Post("/symptoms/log", middleware.ValidateBody[dto.SymptomLogRequest], menstrualHandler.LogSymptoms)
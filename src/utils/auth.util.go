@@ -1,49 +1,208 @@
 package utils
 
 import (
+	"errors"
+	"fmt"
 	"ipincamp/srikandi-sehat/config"
 	"ipincamp/srikandi-sehat/database"
 	"ipincamp/srikandi-sehat/src/models"
 	"log"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/alexedwards/argon2id"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
 )
 
-var argon2Params = &argon2id.Params{
-	Memory:      64 * 1024, // 64 MB
-	Iterations:  3,         // Recommended minimum is 3
-	Parallelism: 2,         // Use 2 threads for efficiency
-	SaltLength:  16,        // 16 bytes salt
-	KeyLength:   32,        // 32 bytes key
+// PasswordHashPolicy adalah satu versi parameter argon2id.
+type PasswordHashPolicy struct {
+	Version int
+	Params  *argon2id.Params
+}
+
+// passwordHashPolicies berisi riwayat parameter hashing password; elemen terakhir adalah
+// kebijakan aktif. Untuk menaikkan parameter, tambahkan versi baru di akhir daftar (jangan
+// mengubah versi lama). Hash lama di-upgrade otomatis saat user berhasil login.
+var passwordHashPolicies = []PasswordHashPolicy{
+	{
+		Version: 1,
+		Params: &argon2id.Params{
+			Memory:      64 * 1024, // 64 MB
+			Iterations:  3,         // Recommended minimum is 3
+			Parallelism: 2,         // Use 2 threads for efficiency
+			SaltLength:  16,        // 16 bytes salt
+			KeyLength:   32,        // 32 bytes key
+		},
+	},
+}
+
+// CurrentPasswordHashPolicy mengembalikan kebijakan hashing password yang aktif.
+func CurrentPasswordHashPolicy() PasswordHashPolicy {
+	return passwordHashPolicies[len(passwordHashPolicies)-1]
 }
 
 func HashPassword(password string) (string, error) {
-	return argon2id.CreateHash(password, argon2Params)
+	return argon2id.CreateHash(password, CurrentPasswordHashPolicy().Params)
 }
 
+// isBcryptHash mendeteksi hash bcrypt dari sistem lama ($2a$, $2b$, $2y$).
+func isBcryptHash(hash string) bool {
+	return strings.HasPrefix(hash, "$2a$") || strings.HasPrefix(hash, "$2b$") || strings.HasPrefix(hash, "$2y$")
+}
+
+// CheckPasswordHash mencocokkan password dengan hash argon2id, atau bcrypt untuk hash lama.
 func CheckPasswordHash(password, hash string) (bool, error) {
+	if isBcryptHash(hash) {
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, nil
+		}
+		return err == nil, err
+	}
 	return argon2id.ComparePasswordAndHash(password, hash)
 }
 
-func GenerateJWT(user models.User) (string, error) {
-	secretKey := config.Get("JWT_SECRET")
+// PasswordHashInfo menjelaskan algoritma dan parameter sebuah hash password.
+// Version bernilai 0 jika hash tidak sesuai dengan kebijakan mana pun (algoritma lama atau parameter asing).
+type PasswordHashInfo struct {
+	Version   int
+	Algorithm string
+	Params    string
+}
 
-	expHoursStr := config.Get("JWT_EXPIRATION_HOURS")
-	expHours, err := strconv.Atoi(expHoursStr)
+// InspectPasswordHash membaca algoritma dan parameter dari hash tanpa memverifikasi password.
+func InspectPasswordHash(hash string) PasswordHashInfo {
+	if isBcryptHash(hash) {
+		info := PasswordHashInfo{Algorithm: "bcrypt"}
+		if cost, err := bcrypt.Cost([]byte(hash)); err == nil {
+			info.Params = fmt.Sprintf("cost=%d", cost)
+		}
+		return info
+	}
+
+	params, salt, key, err := argon2id.DecodeHash(hash)
 	if err != nil {
-		expHours = 24
+		return PasswordHashInfo{Algorithm: "unknown"}
 	}
 
+	info := PasswordHashInfo{
+		Algorithm: "argon2id",
+		Params:    fmt.Sprintf("m=%d,t=%d,p=%d", params.Memory, params.Iterations, params.Parallelism),
+	}
+	for _, policy := range passwordHashPolicies {
+		p := policy.Params
+		if p.Memory == params.Memory && p.Iterations == params.Iterations && p.Parallelism == params.Parallelism &&
+			p.SaltLength == uint32(len(salt)) && p.KeyLength == uint32(len(key)) {
+			info.Version = policy.Version
+		}
+	}
+	return info
+}
+
+// PasswordNeedsRehash bernilai true jika hash tidak dibuat dengan kebijakan aktif.
+func PasswordNeedsRehash(hash string) bool {
+	return InspectPasswordHash(hash).Version != CurrentPasswordHashPolicy().Version
+}
+
+// GenerateJWT membuat access token berumur pendek yang terikat ke sesi (claim "sid").
+// Setiap token memiliki "jti" unik sehingga bisa dicabut satu per satu lewat blocklist.
+func GenerateJWT(user models.User, sessionUUID string) (tokenString string, jti string, expiresAt time.Time, err error) {
+	expMinutesStr := config.Get("JWT_EXPIRATION_MINUTES")
+	expMinutes, err := strconv.Atoi(expMinutesStr)
+	if err != nil || expMinutes <= 0 {
+		expMinutes = 15
+	}
+
+	now := time.Now()
+	jti = uuid.New().String()
+	expiresAt = now.Add(time.Minute * time.Duration(expMinutes))
 	claims := jwt.MapClaims{
+		"jti": jti,
 		"usr": user.UUID,
-		"exp": time.Now().Add(time.Hour * time.Duration(expHours)).Unix(),
+		"sid": sessionUUID,
+		"iat": now.Unix(),
+		"exp": expiresAt.Unix(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secretKey))
+	tokenString, err = SignJWT(claims)
+	return tokenString, jti, expiresAt, err
+}
+
+// TwoFactorChallengeType adalah nilai claim "typ" untuk token tantangan 2FA.
+// Token ini bukan access token dan ditolak oleh AuthMiddleware.
+const TwoFactorChallengeType = "2fa"
+
+// TwoFactorChallenge adalah isi token tantangan 2FA yang sudah divalidasi.
+type TwoFactorChallenge struct {
+	JTI        string
+	UserUUID   string
+	DeviceName string
+	ExpiresAt  time.Time
+}
+
+// GenerateTwoFactorChallenge membuat token sementara setelah password terverifikasi.
+// Token ditukar dengan sesi login setelah langkah kedua (TOTP) selesai, dan "jti"-nya
+// dicabut saat dipakai atau setelah terlalu banyak kode salah.
+func GenerateTwoFactorChallenge(user models.User, deviceName string) (string, time.Time, error) {
+	expiresAt := time.Now().Add(5 * time.Minute)
+	claims := jwt.MapClaims{
+		"typ": TwoFactorChallengeType,
+		"jti": uuid.New().String(),
+		"usr": user.UUID,
+		"dev": deviceName,
+		"exp": expiresAt.Unix(),
+	}
+
+	tokenString, err := SignJWT(claims)
+	return tokenString, expiresAt, err
+}
+
+// ParseTwoFactorChallenge memvalidasi token tantangan 2FA, termasuk memastikan jti-nya belum dicabut.
+func ParseTwoFactorChallenge(tokenString string) (TwoFactorChallenge, error) {
+	token, err := ParseJWT(tokenString)
+	if err != nil || !token.Valid {
+		return TwoFactorChallenge{}, errors.New("invalid or expired challenge token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != TwoFactorChallengeType {
+		return TwoFactorChallenge{}, errors.New("invalid challenge token claims")
+	}
+
+	jti, ok := claims["jti"].(string)
+	if !ok || jti == "" || IsTokenBlocked(jti) {
+		return TwoFactorChallenge{}, errors.New("challenge token has been used or revoked")
+	}
+
+	userUUID, ok := claims["usr"].(string)
+	if !ok {
+		return TwoFactorChallenge{}, errors.New("invalid user identifier in challenge token")
+	}
+	deviceName, _ := claims["dev"].(string)
+
+	expiresAt, err := claims.GetExpirationTime()
+	if err != nil || expiresAt == nil {
+		return TwoFactorChallenge{}, errors.New("invalid expiration in challenge token")
+	}
+
+	return TwoFactorChallenge{
+		JTI:        jti,
+		UserUUID:   userUUID,
+		DeviceName: deviceName,
+		ExpiresAt:  expiresAt.Time,
+	}, nil
+}
+
+// RevokeTwoFactorChallenge mencabut token tantangan agar tidak bisa dipakai lagi,
+// dan menghapus hitungan kode salah miliknya.
+func RevokeTwoFactorChallenge(challenge TwoFactorChallenge) error {
+	if err := RevokeToken(challenge.JTI, challenge.ExpiresAt); err != nil {
+		return err
+	}
+	return ResetLoginFailures(models.LoginLockoutScopeTwoFactorChallenge, challenge.JTI)
 }
 
 func CleanupExpiredTokens() {
@@ -59,5 +218,23 @@ func CleanupExpiredTokens() {
 		} else {
 			log.Printf("%d expired tokens have been deleted.", result.RowsAffected)
 		}
+
+		result = database.DB.Where("expires_at < ?", now).Delete(&models.Session{})
+		if result.Error != nil {
+			log.Printf("Failed to clean up expired sessions: %v", result.Error)
+		} else {
+			log.Printf("%d expired sessions have been deleted.", result.RowsAffected)
+		}
+
+		// Catatan login gagal yang sudah lama dan tidak sedang mengunci apa pun.
+		staleBefore := now.Add(-24 * time.Hour)
+		result = database.DB.
+			Where("last_failed_at < ? AND (locked_until IS NULL OR locked_until < ?)", staleBefore, now).
+			Delete(&models.LoginLockout{})
+		if result.Error != nil {
+			log.Printf("Failed to clean up stale login lockouts: %v", result.Error)
+		} else {
+			log.Printf("%d stale login lockouts have been deleted.", result.RowsAffected)
+		}
 	}
 }
@@ -6,6 +6,8 @@ import (
 	"log"
 	"sync"
 	"time"
+
+	"gorm.io/gorm/clause"
 )
 
 var blocklistCache = make(map[string]struct{})
@@ -20,27 +22,51 @@ func InitializeBlocklistCache() {
 	blocklistMutex.Lock()
 	defer blocklistMutex.Unlock()
 	for _, t := range invalidTokens {
-		blocklistCache[t.Token] = struct{}{}
+		blocklistCache[t.JTI] = struct{}{}
 	}
 
 	log.Printf("%d blocked tokens successfully loaded into cache.", len(invalidTokens))
 }
 
-func AddToBlocklistCache(token string, duration time.Duration) {
+func AddToBlocklistCache(jti string, duration time.Duration) {
 	blocklistMutex.Lock()
-	blocklistCache[token] = struct{}{}
+	blocklistCache[jti] = struct{}{}
 	blocklistMutex.Unlock()
 
 	time.AfterFunc(duration, func() {
 		blocklistMutex.Lock()
-		delete(blocklistCache, token)
+		delete(blocklistCache, jti)
 		blocklistMutex.Unlock()
 	})
 }
 
-func IsTokenBlocked(token string) bool {
+func IsTokenBlocked(jti string) bool {
 	blocklistMutex.RLock()
 	defer blocklistMutex.RUnlock()
-	_, found := blocklistCache[token]
+	_, found := blocklistCache[jti]
 	return found
 }
+
+// RevokeToken memasukkan jti ke tabel invalid_tokens dan cache blocklist hingga token kedaluwarsa.
+func RevokeToken(jti string, expiresAt time.Time) error {
+	if jti == "" || time.Now().After(expiresAt) {
+		return nil
+	}
+
+	invalidToken := models.InvalidToken{
+		JTI:       jti,
+		ExpiresAt: expiresAt,
+	}
+	if err := database.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&invalidToken).Error; err != nil {
+		return err
+	}
+
+	AddToBlocklistCache(jti, time.Until(expiresAt))
+	return nil
+}
+
+// RevokeAllUserTokens mencabut semua sesi aktif milik user beserta access token terakhirnya.
+func RevokeAllUserTokens(userID uint, reason string) error {
+	_, err := RevokeOtherUserSessions(userID, "", reason)
+	return err
+}
@@ -13,8 +13,9 @@ import (
 )
 
 var (
-	roleCache      map[string]models.Role
-	userRolesCache = make(map[string][]string)
+	roleCache            map[string]models.Role
+	userRolesCache       = make(map[string][]string)
+	userPermissionsCache = make(map[string]map[string]struct{})
 
 	provincesCache []dto.RegionResponse
 	regenciesCache map[string][]dto.RegionResponse
@@ -28,28 +29,20 @@ var (
 	maintenanceMutex    = &sync.RWMutex{}
 
 	// Report Token Cache (BARU)
-	reportTokenCache map[string]struct{}
+	// Nilai berisi batas wilayah pembuat token (nil = tanpa batas wilayah).
+	reportTokenCache map[string][]string
 	reportTokenMutex = &sync.RWMutex{}
 
 	cacheMutex = &sync.RWMutex{}
 )
 
 func InitializeRoleCache() {
-	cacheMutex.Lock()
-	defer cacheMutex.Unlock()
-
-	roleCache = make(map[string]models.Role)
-
-	var roles []models.Role
-	if err := database.DB.Find(&roles).Error; err != nil {
+	if err := ReloadRoleCache(); err != nil {
 		log.Fatalf("Failed to load roles into cache: %v", err)
 	}
 
-	for _, role := range roles {
-		roleCache[role.Name] = role
-	}
-
-	log.Printf("Role cache initialized with %d roles", len(roleCache))
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
 
 	var provinces []region.Province
 	database.DB.Find(&provinces)
@@ -95,10 +88,31 @@ func InitializeRoleCache() {
 	log.Println("Maintenance status and whitelist cache initialized.")
 
 	// Initialize Report Token Cache
-	reportTokenCache = make(map[string]struct{})
+	reportTokenCache = make(map[string][]string)
 	log.Println("Report token cache initialized.")
 }
 
+// ReloadRoleCache memuat ulang daftar role dari database. Dipanggil saat startup
+// dan setiap kali role dibuat, diubah, atau dihapus lewat API admin.
+func ReloadRoleCache() error {
+	var roles []models.Role
+	if err := database.DB.Find(&roles).Error; err != nil {
+		return err
+	}
+
+	newRoleCache := make(map[string]models.Role, len(roles))
+	for _, role := range roles {
+		newRoleCache[role.Name] = role
+	}
+
+	cacheMutex.Lock()
+	roleCache = newRoleCache
+	cacheMutex.Unlock()
+
+	log.Printf("Role cache initialized with %d roles", len(newRoleCache))
+	return nil
+}
+
 func GetRoleByName(name string) (models.Role, error) {
 	cacheMutex.RLock()
 	defer cacheMutex.RUnlock()
@@ -142,10 +156,70 @@ func GetUserRoles(userUUID string) ([]string, error) {
 	return roleNames, nil
 }
 
+// InvalidateUserRolesCache menghapus cache role user. Permission efektif ikut dihapus
+// karena sebagian besar permission berasal dari role.
 func InvalidateUserRolesCache(userUUID string) {
 	cacheMutex.Lock()
 	defer cacheMutex.Unlock()
 	delete(userRolesCache, userUUID)
+	delete(userPermissionsCache, userUUID)
+}
+
+// InvalidateAllUserRolesCache mengosongkan cache role (dan permission) semua user,
+// misalnya setelah role diganti nama atau dihapus.
+func InvalidateAllUserRolesCache() {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	userRolesCache = make(map[string][]string)
+	userPermissionsCache = make(map[string]map[string]struct{})
+}
+
+// GetUserPermissions mengembalikan permission efektif user (dari role dan permission langsung).
+// Hasil disimpan di cache sampai dihapus oleh fungsi Invalidate*.
+func GetUserPermissions(userUUID string) (map[string]struct{}, error) {
+	cacheMutex.RLock()
+	permissions, found := userPermissionsCache[userUUID]
+	cacheMutex.RUnlock()
+
+	if found {
+		return permissions, nil
+	}
+
+	var user models.User
+	if err := database.DB.Preload("Roles.Permissions").Preload("Permissions").First(&user, "uuid = ?", userUUID).Error; err != nil {
+		return nil, err
+	}
+
+	permissions = make(map[string]struct{})
+	for _, permission := range user.Permissions {
+		permissions[permission.Name] = struct{}{}
+	}
+	for _, role := range user.Roles {
+		for _, permission := range role.Permissions {
+			permissions[permission.Name] = struct{}{}
+		}
+	}
+
+	cacheMutex.Lock()
+	userPermissionsCache[userUUID] = permissions
+	cacheMutex.Unlock()
+
+	return permissions, nil
+}
+
+// InvalidateUserPermissionsCache menghapus cache permission satu user, misalnya setelah permission langsung diubah.
+func InvalidateUserPermissionsCache(userUUID string) {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	delete(userPermissionsCache, userUUID)
+}
+
+// InvalidateAllUserPermissionsCache mengosongkan cache permission semua user, misalnya setelah
+// permission sebuah role diganti atau sebuah permission diganti nama/dihapus.
+func InvalidateAllUserPermissionsCache() {
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+	userPermissionsCache = make(map[string]map[string]struct{})
 }
 
 func getParentCode(code string) string {
@@ -282,8 +356,14 @@ func IsUserWhitelisted(userUUID string) bool {
 
 // StoreReportToken menyimpan token unik ke cache dan mengatur masa kedaluwarsa.
 func StoreReportToken(token string, expiration time.Duration) {
+	StoreScopedReportToken(token, expiration, nil)
+}
+
+// StoreScopedReportToken sama seperti StoreReportToken, tetapi ikut menyimpan kode wilayah
+// pembuat token agar unduhan (yang tidak membawa JWT) tetap dibatasi ke wilayah tersebut.
+func StoreScopedReportToken(token string, expiration time.Duration, regionCodes []string) {
 	reportTokenMutex.Lock()
-	reportTokenCache[token] = struct{}{}
+	reportTokenCache[token] = regionCodes
 	reportTokenMutex.Unlock()
 
 	// Menjadwalkan penghapusan token setelah kedaluwarsa
@@ -299,15 +379,21 @@ func StoreReportToken(token string, expiration time.Duration) {
 // Jika token ada, token akan dihapus (digunakan) dan mengembalikan true.
 // Jika token tidak ada, mengembalikan false.
 func UseReportToken(token string) bool {
+	_, found := UseScopedReportToken(token)
+	return found
+}
+
+// UseScopedReportToken menggunakan token dan mengembalikan kode wilayah yang disimpan bersamanya.
+func UseScopedReportToken(token string) ([]string, bool) {
 	reportTokenMutex.Lock()
 	defer reportTokenMutex.Unlock()
 
-	if _, found := reportTokenCache[token]; found {
+	if regionCodes, found := reportTokenCache[token]; found {
 		// Token ditemukan, hapus (gunakan) dan kembalikan true
 		delete(reportTokenCache, token)
-		return true
+		return regionCodes, true
 	}
 
 	// Token tidak ditemukan (sudah digunakan atau kedaluwarsa)
-	return false
+	return nil, false
 }
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"ipincamp/srikandi-sehat/config"
+	"ipincamp/srikandi-sehat/database"
+	"ipincamp/srikandi-sehat/src/constants"
+	"ipincamp/srikandi-sehat/src/models"
+	"strconv"
+)
+
+// GuardianConsentAge membaca batas umur (tahun) dari GUARDIAN_CONSENT_AGE.
+// User yang lebih muda dari batas ini membutuhkan persetujuan wali.
+func GuardianConsentAge() int {
+	age, err := strconv.Atoi(config.Get("GUARDIAN_CONSENT_AGE"))
+	if err != nil || age <= 0 {
+		return constants.DefaultGuardianConsentAge
+	}
+	return age
+}
+
+// NewGuardianConsentToken membuat token acak untuk tautan konfirmasi wali beserta hash-nya.
+func NewGuardianConsentToken() (string, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	return token, HashGuardianConsentToken(token), nil
+}
+
+// HashGuardianConsentToken mengembalikan SHA-256 (hex) dari token konfirmasi wali.
+func HashGuardianConsentToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// HasActiveConsent memeriksa apakah user sudah memiliki persetujuan yang sah untuk versi naskah yang berlaku.
+func HasActiveConsent(userUUID string) (bool, error) {
+	var count int64
+	err := database.DB.Model(&models.Consent{}).
+		Joins("JOIN users ON users.id = consents.user_id").
+		Where("users.uuid = ? AND consents.version = ? AND consents.granted_at IS NOT NULL AND consents.revoked_at IS NULL",
+			userUUID, constants.CurrentConsentVersion).
+		Count(&count).Error
+	return count > 0, err
+}
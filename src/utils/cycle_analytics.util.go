@@ -0,0 +1,159 @@
+package utils
+
+import (
+	"ipincamp/srikandi-sehat/src/constants"
+	"ipincamp/srikandi-sehat/src/dto"
+	"ipincamp/srikandi-sehat/src/models/menstrual"
+	"math"
+	"time"
+)
+
+func lengthStats(values []int16) dto.LengthStatsResponse {
+	stats := dto.LengthStatsResponse{Count: len(values)}
+	if len(values) == 0 {
+		return stats
+	}
+
+	floats := make([]float64, 0, len(values))
+	minValue, maxValue := values[0], values[0]
+	var sum float64
+	for _, v := range values {
+		floats = append(floats, float64(v))
+		sum += float64(v)
+		minValue = min(minValue, v)
+		maxValue = max(maxValue, v)
+	}
+
+	mean := math.Round(sum/float64(len(values))*10) / 10
+	stdDev := math.Round(sampleStdDev(floats)*10) / 10
+	stats.Mean = &mean
+	stats.StdDev = &stdDev
+	stats.Min = &minValue
+	stats.Max = &maxValue
+	return stats
+}
+
+// countPattern menghitung berapa kali kondisi terpenuhi dan deret berturut-turut terpanjangnya.
+func countPattern(values []int16, matches func(int16) bool) (occurrences, longestRun int) {
+	run := 0
+	for _, v := range values {
+		if matches(v) {
+			occurrences++
+			run++
+			longestRun = max(longestRun, run)
+		} else {
+			run = 0
+		}
+	}
+	return occurrences, longestRun
+}
+
+func isRecurringPattern(occurrences, longestRun int) bool {
+	return occurrences >= constants.CyclePatternMinOccurrences || longestRun >= constants.CyclePatternMinConsecutive
+}
+
+// AnalyzeCycles menghitung statistik keteraturan siklus dan pola klinis dari siklus dalam jendela analitik
+// (urut dari yang terlama). lastPeriodStart adalah awal haid terakhir user secara keseluruhan (nil jika belum ada),
+// dipakai untuk mendeteksi amenore. Semua ambang diambil dari constants/cycle.go.
+func AnalyzeCycles(cycles []menstrual.MenstrualCycle, lastPeriodStart *time.Time, months int, from, now time.Time) dto.CycleAnalyticsResponse {
+	var cycleLengths, periodLengths []int16
+	for _, cycle := range cycles {
+		if cycle.CycleLength.Valid {
+			cycleLengths = append(cycleLengths, cycle.CycleLength.Int16)
+		}
+		if cycle.PeriodLength.Valid {
+			periodLengths = append(periodLengths, cycle.PeriodLength.Int16)
+		}
+	}
+
+	response := dto.CycleAnalyticsResponse{
+		Months:       months,
+		From:         from,
+		CycleCount:   len(cycles),
+		CycleLength:  lengthStats(cycleLengths),
+		PeriodLength: lengthStats(periodLengths),
+		Regularity:   string(constants.CycleRegularityInsufficientData),
+		Flags:        []dto.CyclePatternFlagResponse{},
+	}
+
+	// Klasifikasi keteraturan berdasarkan selisih siklus terpanjang dan terpendek.
+	if len(cycleLengths) >= constants.CycleAnalyticsMinCycles {
+		variation := *response.CycleLength.Max - *response.CycleLength.Min
+		response.CycleLengthVariation = &variation
+
+		switch {
+		case variation <= constants.CycleRegularMaxVariationDays:
+			response.Regularity = string(constants.CycleRegularityRegular)
+		case variation >= constants.CycleHighlyIrregularDays:
+			response.Regularity = string(constants.CycleRegularityHighlyIrregular)
+		default:
+			response.Regularity = string(constants.CycleRegularityIrregular)
+		}
+
+		if *response.CycleLength.StdDev > constants.CycleHighVariabilityStdDev {
+			response.Flags = append(response.Flags, dto.CyclePatternFlagResponse{
+				Code:        string(constants.CyclePatternHighVariability),
+				Description: "Panjang siklus sangat bervariasi dari satu siklus ke siklus berikutnya.",
+				Occurrences: len(cycleLengths),
+			})
+		}
+	}
+
+	patterns := []struct {
+		code        constants.CyclePatternFlag
+		description string
+		values      []int16
+		matches     func(int16) bool
+	}{
+		{
+			constants.CyclePatternOligomenorrhea,
+			"Siklus lebih panjang dari normal (oligomenorea) terjadi berulang.",
+			cycleLengths,
+			func(v int16) bool { return v > constants.CycleLengthMaxNormalDays },
+		},
+		{
+			constants.CyclePatternPolymenorrhea,
+			"Siklus lebih pendek dari normal (polimenorea) terjadi berulang.",
+			cycleLengths,
+			func(v int16) bool { return v < constants.CycleLengthMinNormalDays },
+		},
+		{
+			constants.CyclePatternMenorrhagia,
+			"Haid berlangsung lebih lama dari normal (menoragia) terjadi berulang.",
+			periodLengths,
+			func(v int16) bool { return v > constants.CyclePeriodMaxNormalDays },
+		},
+		{
+			constants.CyclePatternHypomenorrhea,
+			"Haid berlangsung lebih singkat dari normal (hipomenorea) terjadi berulang.",
+			periodLengths,
+			func(v int16) bool { return v < constants.CyclePeriodMinNormalDays },
+		},
+	}
+	for _, pattern := range patterns {
+		occurrences, longestRun := countPattern(pattern.values, pattern.matches)
+		if isRecurringPattern(occurrences, longestRun) {
+			response.Flags = append(response.Flags, dto.CyclePatternFlagResponse{
+				Code:        string(pattern.code),
+				Description: pattern.description,
+				Occurrences: occurrences,
+				LongestRun:  longestRun,
+			})
+		}
+	}
+
+	if lastPeriodStart != nil {
+		daysSince := int(now.Sub(*lastPeriodStart).Hours() / 24)
+		response.DaysSinceLastPeriod = &daysSince
+
+		if daysSince > constants.CycleAmenorrheaThresholdDays {
+			response.Flags = append(response.Flags, dto.CyclePatternFlagResponse{
+				Code:        string(constants.CyclePatternAmenorrhea),
+				Description: "Belum ada haid tercatat dalam waktu yang lama. Pertimbangkan untuk berkonsultasi dengan tenaga kesehatan.",
+				Occurrences: 1,
+			})
+		}
+	}
+
+	return response
+}
@@ -0,0 +1,202 @@
+package utils
+
+import (
+	"errors"
+	"ipincamp/srikandi-sehat/src/constants"
+	"ipincamp/srikandi-sehat/src/models/menstrual"
+	"math"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CyclePrediction adalah hasil prediksi haid berikutnya beserta rentang dan masa suburnya.
+// Semua tanggal berada di awal hari (00:00) pada zona waktu yang diminta.
+type CyclePrediction struct {
+	SampleSize         int
+	RejectedSamples    int
+	CycleLength        float64
+	StdDev             float64
+	PeriodLength       int
+	PredictedStart     time.Time
+	EarliestStart      time.Time
+	LatestStart        time.Time
+	OvulationDate      time.Time
+	FertileWindowStart time.Time
+	FertileWindowEnd   time.Time
+}
+
+func startOfDay(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	n := len(sorted)
+	if n == 0 {
+		return 0
+	}
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// sampleStdDev menghitung simpangan baku sampel (n-1). Mengembalikan 0 untuk kurang dari dua nilai.
+func sampleStdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(squares / float64(len(values)-1))
+}
+
+// rejectCycleOutliers membuang panjang siklus yang tidak masuk akal dan outlier berbasis MAD.
+// Urutan nilai (terbaru lebih dulu) dipertahankan.
+func rejectCycleOutliers(lengths []float64) []float64 {
+	plausible := make([]float64, 0, len(lengths))
+	for _, length := range lengths {
+		if length >= constants.CyclePredictionMinPlausibleDays && length <= constants.CyclePredictionMaxPlausibleDays {
+			plausible = append(plausible, length)
+		}
+	}
+	if len(plausible) < 3 {
+		return plausible
+	}
+
+	center := median(plausible)
+	deviations := make([]float64, len(plausible))
+	for i, length := range plausible {
+		deviations[i] = math.Abs(length - center)
+	}
+	// 1.4826 membuat MAD sebanding dengan simpangan baku pada distribusi normal.
+	mad := 1.4826 * median(deviations)
+	if mad == 0 {
+		return plausible
+	}
+
+	inliers := make([]float64, 0, len(plausible))
+	for _, length := range plausible {
+		if math.Abs(length-center) <= constants.CyclePredictionOutlierMADs*mad {
+			inliers = append(inliers, length)
+		}
+	}
+	return inliers
+}
+
+// PredictNextPeriod memprediksi haid berikutnya dari panjang siklus (terbaru lebih dulu) dan
+// tanggal mulai haid terakhir. Panjang siklus diestimasi dengan rata-rata berbobot (siklus terbaru
+// berbobot lebih besar) setelah outlier dibuang; rentang prediksi mengikuti variasi siklus user.
+// ok bernilai false jika tidak ada panjang siklus yang bisa dipakai.
+func PredictNextPeriod(cycleLengths []int16, lastStart time.Time) (prediction CyclePrediction, ok bool) {
+	return PredictNextPeriodIn(cycleLengths, lastStart, time.Local)
+}
+
+// PredictNextPeriodIn sama seperti PredictNextPeriod, tetapi tanggal dihitung pada zona waktu loc.
+func PredictNextPeriodIn(cycleLengths []int16, lastStart time.Time, loc *time.Location) (prediction CyclePrediction, ok bool) {
+	samples := make([]float64, 0, len(cycleLengths))
+	for _, length := range cycleLengths {
+		samples = append(samples, float64(length))
+	}
+	if len(samples) > constants.CyclePredictionMaxSamples {
+		samples = samples[:constants.CyclePredictionMaxSamples]
+	}
+
+	inliers := rejectCycleOutliers(samples)
+	if len(inliers) == 0 {
+		return CyclePrediction{}, false
+	}
+
+	var weightedSum, totalWeight float64
+	for i, length := range inliers {
+		weight := float64(len(inliers) - i)
+		weightedSum += weight * length
+		totalWeight += weight
+	}
+	estimate := weightedSum / totalWeight
+
+	stdDev := sampleStdDev(inliers)
+	spread := stdDev
+	if len(inliers) < 3 {
+		spread = math.Max(stdDev, constants.CyclePredictionDefaultStdDevDays)
+	}
+	rangeDays := int(math.Max(math.Round(spread), constants.CyclePredictionMinRangeDays))
+
+	base := startOfDay(lastStart, loc)
+	predicted := base.AddDate(0, 0, int(math.Round(estimate)))
+	ovulation := predicted.AddDate(0, 0, -constants.CycleLutealPhaseDays)
+
+	return CyclePrediction{
+		SampleSize:         len(inliers),
+		RejectedSamples:    len(samples) - len(inliers),
+		CycleLength:        math.Round(estimate*10) / 10,
+		StdDev:             math.Round(stdDev*10) / 10,
+		PeriodLength:       constants.CyclePredictionDefaultPeriodDays,
+		PredictedStart:     predicted,
+		EarliestStart:      predicted.AddDate(0, 0, -rangeDays),
+		LatestStart:        predicted.AddDate(0, 0, rangeDays),
+		OvulationDate:      ovulation,
+		FertileWindowStart: ovulation.AddDate(0, 0, -constants.CycleFertileDaysBeforeOvulation),
+		FertileWindowEnd:   ovulation.AddDate(0, 0, constants.CycleFertileDaysAfterOvulation),
+	}, true
+}
+
+// PredictNextPeriodForUser mengambil riwayat siklus user dari database lalu memanggil PredictNextPeriod.
+// Dipakai oleh handler status siklus maupun worker.
+func PredictNextPeriodForUser(db *gorm.DB, userID uint) (CyclePrediction, bool, error) {
+	return PredictNextPeriodForUserIn(db, userID, time.Local)
+}
+
+// PredictNextPeriodForUserIn sama seperti PredictNextPeriodForUser dengan tanggal pada zona waktu loc.
+// Lama haid prediksi diambil dari median lama haid siklus-siklus terakhir.
+func PredictNextPeriodForUserIn(db *gorm.DB, userID uint, loc *time.Location) (CyclePrediction, bool, error) {
+	var latestCycle menstrual.MenstrualCycle
+	if err := db.Where("user_id = ?", userID).Order("start_date desc").First(&latestCycle).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return CyclePrediction{}, false, nil
+		}
+		return CyclePrediction{}, false, err
+	}
+
+	var cycleLengths []int16
+	if err := db.Model(&menstrual.MenstrualCycle{}).
+		Where("user_id = ? AND cycle_length IS NOT NULL", userID).
+		Order("start_date desc").
+		Limit(constants.CyclePredictionMaxSamples).
+		Pluck("cycle_length", &cycleLengths).Error; err != nil {
+		return CyclePrediction{}, false, err
+	}
+
+	prediction, ok := PredictNextPeriodIn(cycleLengths, latestCycle.StartDate, loc)
+	if !ok {
+		return prediction, false, nil
+	}
+
+	var periodLengths []float64
+	if err := db.Model(&menstrual.MenstrualCycle{}).
+		Where("user_id = ? AND period_length IS NOT NULL", userID).
+		Order("start_date desc").
+		Limit(constants.CyclePredictionMaxSamples).
+		Pluck("period_length", &periodLengths).Error; err != nil {
+		return CyclePrediction{}, false, err
+	}
+	if len(periodLengths) > 0 {
+		prediction.PeriodLength = int(math.Round(median(periodLengths)))
+	}
+
+	return prediction, true, nil
+}
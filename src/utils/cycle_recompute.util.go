@@ -0,0 +1,165 @@
+package utils
+
+import (
+	"database/sql"
+	"fmt"
+	"ipincamp/srikandi-sehat/src/constants"
+	"ipincamp/srikandi-sehat/src/models/menstrual"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CalculatePeriodLength menghitung lama haid (dalam hari kalender, inklusif) dan apakah lamanya normal.
+func CalculatePeriodLength(startDate, endDate time.Time) (int16, bool) {
+	loc := time.Local
+
+	startDay := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, loc)
+	endDay := time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, loc)
+
+	periodLength := int16(endDay.Sub(startDay).Hours()/24) + 1
+	isNormal := periodLength >= constants.CyclePeriodMinNormalDays && periodLength <= constants.CyclePeriodMaxNormalDays
+	return periodLength, isNormal
+}
+
+// CalculateCycleLength menghitung panjang siklus dari tanggal mulai ke tanggal mulai siklus berikutnya.
+func CalculateCycleLength(startDate, nextStartDate time.Time) (int16, bool) {
+	cycleLength := int16(nextStartDate.Sub(startDate).Hours() / 24)
+	isNormal := cycleLength >= constants.CycleLengthMinNormalDays && cycleLength <= constants.CycleLengthMaxNormalDays
+	return cycleLength, isNormal
+}
+
+// CycleFieldChange adalah satu perbedaan antara nilai tersimpan dan nilai hasil hitung ulang.
+type CycleFieldChange struct {
+	Table    string
+	RowID    uint
+	Column   string
+	OldValue string
+	NewValue string
+	value    interface{}
+}
+
+func (c CycleFieldChange) String() string {
+	return fmt.Sprintf("%s #%d %s: %s -> %s", c.Table, c.RowID, c.Column, c.OldValue, c.NewValue)
+}
+
+func formatNullInt16(v sql.NullInt16) string {
+	if !v.Valid {
+		return "NULL"
+	}
+	return fmt.Sprintf("%d", v.Int16)
+}
+
+func formatNullBool(v sql.NullBool) string {
+	if !v.Valid {
+		return "NULL"
+	}
+	return fmt.Sprintf("%t", v.Bool)
+}
+
+func formatNullInt64(v sql.NullInt64) string {
+	if !v.Valid {
+		return "NULL"
+	}
+	return fmt.Sprintf("%d", v.Int64)
+}
+
+// PlanCycleRecompute menghitung ulang field turunan siklus (lama haid, panjang siklus, flag normal)
+// serta tautan siklus pada log gejala dan catatan aliran milik satu user, lalu mengembalikan daftar
+// perbedaannya tanpa mengubah data.
+func PlanCycleRecompute(db *gorm.DB, userID uint) ([]CycleFieldChange, error) {
+	var cycles []menstrual.MenstrualCycle
+	if err := db.Where("user_id = ?", userID).Order("start_date asc").Find(&cycles).Error; err != nil {
+		return nil, err
+	}
+
+	var changes []CycleFieldChange
+	addInt16 := func(id uint, column string, old, expected sql.NullInt16) {
+		if old != expected {
+			changes = append(changes, CycleFieldChange{"menstrual_cycles", id, column, formatNullInt16(old), formatNullInt16(expected), expected})
+		}
+	}
+	addBool := func(id uint, column string, old, expected sql.NullBool) {
+		if old != expected {
+			changes = append(changes, CycleFieldChange{"menstrual_cycles", id, column, formatNullBool(old), formatNullBool(expected), expected})
+		}
+	}
+
+	for i, cycle := range cycles {
+		var periodLength sql.NullInt16
+		var isPeriodNormal sql.NullBool
+		if cycle.EndDate.Valid {
+			length, normal := CalculatePeriodLength(cycle.StartDate, cycle.EndDate.Time)
+			periodLength = sql.NullInt16{Int16: length, Valid: true}
+			isPeriodNormal = sql.NullBool{Bool: normal, Valid: true}
+		}
+
+		var cycleLength sql.NullInt16
+		var isCycleNormal sql.NullBool
+		if i+1 < len(cycles) {
+			length, normal := CalculateCycleLength(cycle.StartDate, cycles[i+1].StartDate)
+			cycleLength = sql.NullInt16{Int16: length, Valid: true}
+			isCycleNormal = sql.NullBool{Bool: normal, Valid: true}
+		}
+
+		addInt16(cycle.ID, "period_length", cycle.PeriodLength, periodLength)
+		addBool(cycle.ID, "is_period_normal", cycle.IsPeriodNormal, isPeriodNormal)
+		addInt16(cycle.ID, "cycle_length", cycle.CycleLength, cycleLength)
+		addBool(cycle.ID, "is_cycle_normal", cycle.IsCycleNormal, isCycleNormal)
+	}
+
+	var logs []menstrual.SymptomLog
+	if err := db.Select("id", "logged_at", "menstrual_cycle_id").Where("user_id = ?", userID).Find(&logs).Error; err != nil {
+		return nil, err
+	}
+	for _, log := range logs {
+		// Sama seperti saat log dibuat: siklus dengan tanggal mulai terbaru yang mencakup waktu log.
+		var expected sql.NullInt64
+		for i := len(cycles) - 1; i >= 0; i-- {
+			cycle := cycles[i]
+			if !cycle.StartDate.After(log.LoggedAt) && (!cycle.EndDate.Valid || !cycle.EndDate.Time.Before(log.LoggedAt)) {
+				expected = sql.NullInt64{Int64: int64(cycle.ID), Valid: true}
+				break
+			}
+		}
+		if log.MenstrualCycleID != expected {
+			changes = append(changes, CycleFieldChange{"symptom_logs", log.ID, "menstrual_cycle_id", formatNullInt64(log.MenstrualCycleID), formatNullInt64(expected), expected})
+		}
+	}
+
+	var flowLogs []menstrual.FlowLog
+	if err := db.Select("id", "date", "is_intermenstrual", "menstrual_cycle_id").Where("user_id = ?", userID).Find(&flowLogs).Error; err != nil {
+		return nil, err
+	}
+	for _, flowLog := range flowLogs {
+		// Sama seperti saat catatan aliran dibuat: siklus dengan tanggal mulai terbaru yang dimulai
+		// sebelum hari berikutnya dan belum berakhir sebelum hari tersebut.
+		day := time.Date(flowLog.Date.Year(), flowLog.Date.Month(), flowLog.Date.Day(), 0, 0, 0, 0, time.Local)
+		var expected sql.NullInt64
+		for i := len(cycles) - 1; i >= 0; i-- {
+			cycle := cycles[i]
+			if cycle.StartDate.Before(day.AddDate(0, 0, 1)) && (!cycle.EndDate.Valid || !cycle.EndDate.Time.Before(day)) {
+				expected = sql.NullInt64{Int64: int64(cycle.ID), Valid: true}
+				break
+			}
+		}
+		if flowLog.MenstrualCycleID != expected {
+			changes = append(changes, CycleFieldChange{"flow_logs", flowLog.ID, "menstrual_cycle_id", formatNullInt64(flowLog.MenstrualCycleID), formatNullInt64(expected), expected})
+		}
+		if isIntermenstrual := !expected.Valid; flowLog.IsIntermenstrual != isIntermenstrual {
+			changes = append(changes, CycleFieldChange{"flow_logs", flowLog.ID, "is_intermenstrual", fmt.Sprintf("%t", flowLog.IsIntermenstrual), fmt.Sprintf("%t", isIntermenstrual), isIntermenstrual})
+		}
+	}
+
+	return changes, nil
+}
+
+// ApplyCycleRecompute menyimpan perubahan hasil PlanCycleRecompute. Panggil di dalam transaksi.
+func ApplyCycleRecompute(tx *gorm.DB, changes []CycleFieldChange) error {
+	for _, change := range changes {
+		if err := tx.Table(change.Table).Where("id = ?", change.RowID).Update(change.Column, change.value).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"html"
 	"ipincamp/srikandi-sehat/config"
 	"strconv"
 	"time"
@@ -46,18 +47,21 @@ func SendEmail(to, subject, htmlBody string) error {
 	return nil
 }
 
-// SendVerificationOTPEmail membuat template HTML dan memanggil SendEmail.
-func SendVerificationOTPEmail(toEmail, otp string, expiresAt time.Time) error {
-	subject := "Kode Verifikasi Akun Srikandi Sehat Anda"
-
-	// --- 2. FORMAT WAKTU KEDALUWARSA ---
+// formatEmailTime memformat waktu di zona TIMEZONE, contoh: "14:35:02 WIB (30 October 2025)".
+func formatEmailTime(t time.Time) string {
 	loc, err := time.LoadLocation(config.Get("TIMEZONE"))
 	if err != nil {
 		// Jika gagal, fallback ke Waktu Server Lokal
 		loc = time.Local
 	}
-	// Format waktu agar jelas: "14:35:02 WIB (30 Oktober 2025)"
-	formattedTime := expiresAt.In(loc).Format("15:04:05 MST (2 January 2006)")
+	return t.In(loc).Format("15:04:05 MST (2 January 2006)")
+}
+
+// SendVerificationOTPEmail membuat template HTML dan memanggil SendEmail.
+func SendVerificationOTPEmail(toEmail, otp string, expiresAt time.Time) error {
+	subject := "Kode Verifikasi Akun Srikandi Sehat Anda"
+
+	formattedTime := formatEmailTime(expiresAt)
 
 	// Buat template HTML sederhana untuk email
 	htmlBody := fmt.Sprintf(`
@@ -81,3 +85,149 @@ func SendVerificationOTPEmail(toEmail, otp string, expiresAt time.Time) error {
 	// Panggil pengirim email inti
 	return SendEmail(toEmail, subject, htmlBody)
 }
+
+// SendPasswordResetOTPEmail mengirim kode OTP untuk reset password.
+func SendPasswordResetOTPEmail(toEmail, otp string, expiresAt time.Time) error {
+	subject := "Kode Reset Password Srikandi Sehat"
+
+	htmlBody := fmt.Sprintf(`
+	<div style="font-family: Arial, sans-serif; line-height: 1.6;">
+		<h2>Reset Password Akun Srikandi Sehat</h2>
+		<p>Kami menerima permintaan untuk mengatur ulang password akun Anda. Gunakan kode OTP berikut:</p>
+		<p style="font-size: 28px; font-weight: bold; letter-spacing: 4px; color: #333;">
+			%s
+		</p>
+		<p style="color: #888;">
+			Kode ini akan kedaluwarsa pada:<br>
+			<strong style="color: #D9534F;">%s</strong>
+		</p>
+		<p>Jika Anda tidak meminta reset password, abaikan email ini. Password Anda tidak akan berubah.</p>
+		<br>
+		<p>Salam,</p>
+		<p>Tim Srikandi Sehat</p>
+	</div>
+	`, otp, formatEmailTime(expiresAt))
+
+	return SendEmail(toEmail, subject, htmlBody)
+}
+
+// SendAccountDeletionScheduledEmail memberi tahu user bahwa akunnya dijadwalkan untuk dihapus.
+func SendAccountDeletionScheduledEmail(toEmail string, scheduledAt time.Time) error {
+	subject := "Permintaan Penghapusan Akun Srikandi Sehat"
+
+	htmlBody := fmt.Sprintf(`
+	<div style="font-family: Arial, sans-serif; line-height: 1.6;">
+		<h2>Permintaan Penghapusan Akun</h2>
+		<p>Kami menerima permintaan untuk menghapus akun Srikandi Sehat Anda beserta seluruh data kesehatan di dalamnya.</p>
+		<p style="color: #888;">
+			Akun dan data Anda akan dihapus permanen pada:<br>
+			<strong style="color: #D9534F;">%s</strong>
+		</p>
+		<p>Jika Anda berubah pikiran, masuk ke aplikasi dan batalkan permintaan penghapusan sebelum waktu tersebut.</p>
+		<br>
+		<p>Salam,</p>
+		<p>Tim Srikandi Sehat</p>
+	</div>
+	`, formatEmailTime(scheduledAt))
+
+	return SendEmail(toEmail, subject, htmlBody)
+}
+
+// SendEmailChangeOTPEmail mengirim kode OTP ke alamat email baru untuk konfirmasi perubahan email.
+func SendEmailChangeOTPEmail(toEmail, otp string, expiresAt time.Time) error {
+	subject := "Konfirmasi Perubahan Email Srikandi Sehat"
+
+	htmlBody := fmt.Sprintf(`
+	<div style="font-family: Arial, sans-serif; line-height: 1.6;">
+		<h2>Konfirmasi Alamat Email Baru</h2>
+		<p>Kami menerima permintaan untuk mengganti email akun Srikandi Sehat menjadi alamat ini. Gunakan kode OTP berikut untuk mengonfirmasi:</p>
+		<p style="font-size: 28px; font-weight: bold; letter-spacing: 4px; color: #333;">
+			%s
+		</p>
+		<p style="color: #888;">
+			Kode ini akan kedaluwarsa pada:<br>
+			<strong style="color: #D9534F;">%s</strong>
+		</p>
+		<p>Jika Anda tidak meminta perubahan ini, abaikan email ini.</p>
+		<br>
+		<p>Salam,</p>
+		<p>Tim Srikandi Sehat</p>
+	</div>
+	`, otp, formatEmailTime(expiresAt))
+
+	return SendEmail(toEmail, subject, htmlBody)
+}
+
+// SendEmailChangedNoticeEmail memberi tahu alamat email lama bahwa email akun telah diganti.
+func SendEmailChangedNoticeEmail(toEmail, newEmail string, changedAt time.Time) error {
+	subject := "Email Akun Srikandi Sehat Anda Telah Diubah"
+
+	htmlBody := fmt.Sprintf(`
+	<div style="font-family: Arial, sans-serif; line-height: 1.6;">
+		<h2>Email Akun Telah Diubah</h2>
+		<p>Email akun Srikandi Sehat Anda telah diganti menjadi <strong>%s</strong>.</p>
+		<p style="color: #888;">
+			Waktu perubahan:<br>
+			<strong>%s</strong>
+		</p>
+		<p>Jika Anda tidak melakukan perubahan ini, segera hubungi tim kami.</p>
+		<br>
+		<p>Salam,</p>
+		<p>Tim Srikandi Sehat</p>
+	</div>
+	`, newEmail, formatEmailTime(changedAt))
+
+	return SendEmail(toEmail, subject, htmlBody)
+}
+
+// SendAccountLockedEmail memberi tahu pemilik akun bahwa akunnya dikunci sementara
+// karena terlalu banyak percobaan login yang gagal.
+func SendAccountLockedEmail(toEmail string, lockedUntil time.Time) error {
+	subject := "Akun Srikandi Sehat Anda Dikunci Sementara"
+
+	htmlBody := fmt.Sprintf(`
+	<div style="font-family: Arial, sans-serif; line-height: 1.6;">
+		<h2>Akun Dikunci Sementara</h2>
+		<p>Kami mendeteksi beberapa percobaan login yang gagal pada akun Srikandi Sehat Anda. Demi keamanan, akun Anda dikunci sementara.</p>
+		<p style="color: #888;">
+			Anda dapat mencoba login kembali setelah:<br>
+			<strong style="color: #D9534F;">%s</strong>
+		</p>
+		<p>Jika percobaan tersebut bukan dari Anda, sebaiknya segera ganti password setelah kunci berakhir atau gunakan fitur lupa password.</p>
+		<br>
+		<p>Salam,</p>
+		<p>Tim Srikandi Sehat</p>
+	</div>
+	`, formatEmailTime(lockedUntil))
+
+	return SendEmail(toEmail, subject, htmlBody)
+}
+
+// SendGuardianConsentEmail mengirim tautan konfirmasi persetujuan kepada wali user di bawah umur.
+func SendGuardianConsentEmail(toEmail, guardianName, childName, consentLink string, expiresAt time.Time) error {
+	subject := "Permintaan Persetujuan Wali - Srikandi Sehat"
+
+	htmlBody := fmt.Sprintf(`
+	<div style="font-family: Arial, sans-serif; line-height: 1.6;">
+		<h2>Permintaan Persetujuan Wali</h2>
+		<p>Yth. %s,</p>
+		<p><strong>%s</strong> mendaftar di aplikasi Srikandi Sehat dan mencantumkan Anda sebagai orang tua/wali.
+		Karena masih di bawah umur, kami membutuhkan persetujuan Anda sebelum data kesehatan menstruasinya dicatat.</p>
+		<p>
+			<a href="%s" style="display: inline-block; padding: 10px 20px; background-color: #D63384; color: #fff; text-decoration: none; border-radius: 4px;">
+				Tinjau dan Berikan Persetujuan
+			</a>
+		</p>
+		<p style="color: #888;">
+			Tautan ini akan kedaluwarsa pada:<br>
+			<strong style="color: #D9534F;">%s</strong>
+		</p>
+		<p>Jika Anda tidak mengenal permintaan ini, abaikan email ini.</p>
+		<br>
+		<p>Salam,</p>
+		<p>Tim Srikandi Sehat</p>
+	</div>
+	`, html.EscapeString(guardianName), html.EscapeString(childName), consentLink, formatEmailTime(expiresAt))
+
+	return SendEmail(toEmail, subject, htmlBody)
+}
@@ -0,0 +1,300 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"ipincamp/srikandi-sehat/config"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrNoJWTKeys dikembalikan jika belum ada kunci JWT yang dikonfigurasi sama sekali.
+var ErrNoJWTKeys = errors.New("no JWT signing keys configured")
+
+// Kunci bawaan dari JWT_SECRET. Token lama yang dibuat sebelum ada header "kid"
+// diverifikasi dengan kunci ini.
+const defaultJWTKeyID = "default"
+
+// jwtKey adalah satu kunci penandatangan JWT. Kunci HMAC tidak pernah dipublikasikan,
+// sedangkan kunci asimetris (EdDSA/RS256) dipublikasikan lewat JWKS.
+type jwtKey struct {
+	kid       string
+	method    jwt.SigningMethod
+	signKey   interface{} // nil jika kunci hanya untuk verifikasi
+	verifyKey interface{}
+	publicKey crypto.PublicKey // nil untuk HMAC
+}
+
+type jwtKeyRing struct {
+	activeKID string
+	keys      map[string]*jwtKey
+	order     []string // urutan kid untuk JWKS yang stabil
+}
+
+var (
+	keyRing     *jwtKeyRing
+	keyRingErr  error
+	keyRingOnce sync.Once
+)
+
+// JWK adalah representasi public key sesuai RFC 7517.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+// InitializeJWTKeys memuat seluruh kunci JWT dari konfigurasi. Dipanggil saat startup
+// agar kesalahan konfigurasi kunci langsung terlihat.
+//
+//	JWT_SECRET              kunci HMAC bawaan (kid "default")
+//	JWT_HMAC_KEYS           kunci HMAC tambahan, format "kid:secret,kid2:secret2"
+//	JWT_PRIVATE_KEY_FILES   kunci privat PEM (Ed25519 atau RSA), format "kid=path,kid2=path"
+//	JWT_PUBLIC_KEY_FILES    kunci publik PEM untuk kunci yang sudah pensiun, format "kid=path"
+//	JWT_ACTIVE_KID          kid yang dipakai untuk menandatangani token baru (default "default")
+func InitializeJWTKeys() error {
+	keyRingOnce.Do(func() {
+		keyRing, keyRingErr = loadJWTKeyRing()
+	})
+	return keyRingErr
+}
+
+func getJWTKeyRing() (*jwtKeyRing, error) {
+	if err := InitializeJWTKeys(); err != nil {
+		return nil, err
+	}
+	return keyRing, nil
+}
+
+func loadJWTKeyRing() (*jwtKeyRing, error) {
+	ring := &jwtKeyRing{keys: make(map[string]*jwtKey)}
+
+	add := func(key *jwtKey) error {
+		if _, exists := ring.keys[key.kid]; exists {
+			return fmt.Errorf("duplicate JWT key id %q", key.kid)
+		}
+		ring.keys[key.kid] = key
+		ring.order = append(ring.order, key.kid)
+		return nil
+	}
+
+	if secret := config.Get("JWT_SECRET"); secret != "" {
+		add(&jwtKey{kid: defaultJWTKeyID, method: jwt.SigningMethodHS256, signKey: []byte(secret), verifyKey: []byte(secret)})
+	}
+
+	for _, entry := range parseKeyList(config.Get("JWT_HMAC_KEYS"), ":") {
+		secret := []byte(entry.value)
+		if err := add(&jwtKey{kid: entry.kid, method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, entry := range parseKeyList(config.Get("JWT_PRIVATE_KEY_FILES"), "=") {
+		key, err := loadPrivateKeyFile(entry.kid, entry.value)
+		if err != nil {
+			return nil, err
+		}
+		if err := add(key); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, entry := range parseKeyList(config.Get("JWT_PUBLIC_KEY_FILES"), "=") {
+		key, err := loadPublicKeyFile(entry.kid, entry.value)
+		if err != nil {
+			return nil, err
+		}
+		if err := add(key); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(ring.keys) == 0 {
+		return nil, ErrNoJWTKeys
+	}
+
+	ring.activeKID = config.Get("JWT_ACTIVE_KID")
+	if ring.activeKID == "" {
+		ring.activeKID = defaultJWTKeyID
+	}
+	active, ok := ring.keys[ring.activeKID]
+	if !ok {
+		return nil, fmt.Errorf("active JWT key %q is not configured", ring.activeKID)
+	}
+	if active.signKey == nil {
+		return nil, fmt.Errorf("active JWT key %q has no private key", ring.activeKID)
+	}
+
+	return ring, nil
+}
+
+type keyListEntry struct {
+	kid   string
+	value string
+}
+
+// parseKeyList mengurai daftar "kid<sep>value" yang dipisahkan koma.
+// Urutan kemunculan dipertahankan agar urutan JWKS konsisten.
+func parseKeyList(raw, sep string) []keyListEntry {
+	var entries []keyListEntry
+	for _, entry := range strings.Split(raw, ",") {
+		kid, value, found := strings.Cut(strings.TrimSpace(entry), sep)
+		kid = strings.TrimSpace(kid)
+		if !found || kid == "" {
+			continue
+		}
+		entries = append(entries, keyListEntry{kid: kid, value: strings.TrimSpace(value)})
+	}
+	return entries
+}
+
+func readPEMFile(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", path)
+	}
+	return block, nil
+}
+
+func loadPrivateKeyFile(kid, path string) (*jwtKey, error) {
+	block, err := readPEMFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("JWT key %q: %w", kid, err)
+	}
+
+	var parsed interface{}
+	parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("JWT key %q: unsupported private key format", kid)
+		}
+	}
+
+	switch privateKey := parsed.(type) {
+	case ed25519.PrivateKey:
+		publicKey := privateKey.Public().(ed25519.PublicKey)
+		return &jwtKey{kid: kid, method: jwt.SigningMethodEdDSA, signKey: privateKey, verifyKey: publicKey, publicKey: publicKey}, nil
+	case *rsa.PrivateKey:
+		return &jwtKey{kid: kid, method: jwt.SigningMethodRS256, signKey: privateKey, verifyKey: &privateKey.PublicKey, publicKey: &privateKey.PublicKey}, nil
+	default:
+		return nil, fmt.Errorf("JWT key %q: only Ed25519 and RSA keys are supported", kid)
+	}
+}
+
+func loadPublicKeyFile(kid, path string) (*jwtKey, error) {
+	block, err := readPEMFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("JWT key %q: %w", kid, err)
+	}
+
+	var parsed interface{}
+	parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("JWT key %q: unsupported public key format", kid)
+		}
+	}
+
+	switch publicKey := parsed.(type) {
+	case ed25519.PublicKey:
+		return &jwtKey{kid: kid, method: jwt.SigningMethodEdDSA, verifyKey: publicKey, publicKey: publicKey}, nil
+	case *rsa.PublicKey:
+		return &jwtKey{kid: kid, method: jwt.SigningMethodRS256, verifyKey: publicKey, publicKey: publicKey}, nil
+	default:
+		return nil, fmt.Errorf("JWT key %q: only Ed25519 and RSA keys are supported", kid)
+	}
+}
+
+// SignJWT menandatangani claims dengan kunci aktif dan menambahkan header "kid".
+func SignJWT(claims jwt.Claims) (string, error) {
+	ring, err := getJWTKeyRing()
+	if err != nil {
+		return "", err
+	}
+
+	key := ring.keys[ring.activeKID]
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+	return token.SignedString(key.signKey)
+}
+
+// ParseJWT memverifikasi token dengan kunci yang ditunjuk header "kid".
+// Algoritma token harus sama dengan algoritma kunci untuk mencegah serangan algorithm confusion.
+func ParseJWT(tokenString string) (*jwt.Token, error) {
+	ring, err := getJWTKeyRing()
+	if err != nil {
+		return nil, err
+	}
+
+	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			kid = defaultJWTKeyID
+		}
+
+		key, ok := ring.keys[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown signing key: %s", kid)
+		}
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.verifyKey, nil
+	})
+}
+
+// GetJWKS mengembalikan public key seluruh kunci asimetris (aktif maupun pensiun).
+// Kunci HMAC bersifat rahasia sehingga tidak pernah ikut dipublikasikan.
+func GetJWKS() ([]JWK, error) {
+	ring, err := getJWTKeyRing()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]JWK, 0, len(ring.order))
+	for _, kid := range ring.order {
+		key := ring.keys[kid]
+		switch publicKey := key.publicKey.(type) {
+		case ed25519.PublicKey:
+			keys = append(keys, JWK{
+				Kty: "OKP",
+				Kid: kid,
+				Use: "sig",
+				Alg: key.method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(publicKey),
+			})
+		case *rsa.PublicKey:
+			keys = append(keys, JWK{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				Alg: key.method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+			})
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"database/sql"
+	"errors"
+	"ipincamp/srikandi-sehat/database"
+	"ipincamp/srikandi-sehat/src/constants"
+	"ipincamp/srikandi-sehat/src/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// loginLockoutDuration menghitung durasi kunci ke-n: base, 2x base, 4x base, ... dibatasi maksimum.
+func loginLockoutDuration(lockoutCount uint) time.Duration {
+	minutes := constants.LoginLockoutBaseMinutes
+	for i := uint(1); i < lockoutCount && minutes < constants.LoginLockoutMaxMinutes; i++ {
+		minutes *= 2
+	}
+	if minutes > constants.LoginLockoutMaxMinutes {
+		minutes = constants.LoginLockoutMaxMinutes
+	}
+	return time.Duration(minutes) * time.Minute
+}
+
+// GetLoginLockout mengembalikan waktu berakhirnya kunci jika scope/identifier sedang terkunci.
+func GetLoginLockout(scope, identifier string) (time.Time, bool) {
+	var lockout models.LoginLockout
+	err := database.DB.Select("locked_until").
+		Where("scope = ? AND identifier = ?", scope, identifier).
+		First(&lockout).Error
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			ErrorLogger.Printf("Failed to read login lockout (%s %s): %v", scope, identifier, err)
+		}
+		return time.Time{}, false
+	}
+
+	if lockout.LockedUntil.Valid && time.Now().Before(lockout.LockedUntil.Time) {
+		return lockout.LockedUntil.Time, true
+	}
+	return time.Time{}, false
+}
+
+// RecordLoginFailure menambah hitungan login gagal. Jika batas tercapai, scope/identifier
+// dikunci dan fungsi mengembalikan waktu berakhirnya kunci dengan locked = true.
+func RecordLoginFailure(scope, identifier string, maxAttempts uint) (lockedUntil time.Time, locked bool, err error) {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		var lockout models.LoginLockout
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("scope = ? AND identifier = ?", scope, identifier).
+			First(&lockout).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			lockout = models.LoginLockout{Scope: scope, Identifier: identifier, LastFailedAt: now}
+		} else if err != nil {
+			return err
+		}
+
+		// Kegagalan lama di luar jendela waktu tidak dihitung lagi.
+		if now.Sub(lockout.LastFailedAt) > constants.LoginFailureWindowMinutes*time.Minute {
+			lockout.FailedAttempts = 0
+		}
+
+		lockout.FailedAttempts++
+		lockout.LastFailedAt = now
+
+		if lockout.FailedAttempts >= maxAttempts {
+			lockout.LockoutCount++
+			lockout.FailedAttempts = 0
+			lockedUntil = now.Add(loginLockoutDuration(lockout.LockoutCount))
+			lockout.LockedUntil = sql.NullTime{Time: lockedUntil, Valid: true}
+			locked = true
+		}
+
+		return tx.Save(&lockout).Error
+	})
+	return lockedUntil, locked, err
+}
+
+// ResetLoginFailures menghapus hitungan gagal dan kunci, misalnya setelah login sukses
+// atau saat admin membuka kunci akun.
+func ResetLoginFailures(scope, identifier string) error {
+	return database.DB.Where("scope = ? AND identifier = ?", scope, identifier).
+		Delete(&models.LoginLockout{}).Error
+}
@@ -3,6 +3,11 @@ package utils
 import (
 	"crypto/rand"
 	"io"
+	"ipincamp/srikandi-sehat/database"
+	"ipincamp/srikandi-sehat/src/constants"
+	"ipincamp/srikandi-sehat/src/models"
+
+	"gorm.io/gorm"
 )
 
 // GenerateOTP menghasilkan kode numerik acak dengan panjang yang ditentukan.
@@ -20,3 +25,29 @@ func GenerateOTP(length int) (string, error) {
 
 	return string(buffer), nil
 }
+
+// HashOTP meng-hash kode OTP dengan argon2id sebelum disimpan ke database.
+func HashOTP(otp string) (string, error) {
+	return HashPassword(otp)
+}
+
+// CheckOTP membandingkan OTP yang dikirim user dengan hash yang tersimpan.
+// Hash yang tidak valid (misalnya OTP lama yang masih plaintext) dianggap tidak cocok.
+func CheckOTP(otp, hash string) bool {
+	match, err := CheckPasswordHash(otp, hash)
+	return err == nil && match
+}
+
+// ConsumeOTPAttempt menaikkan penghitung percobaan OTP milik user secara atomik, dan hanya jika
+// OTP yang akan dibandingkan (tokenHash) masih tersimpan dan batas constants.OTPMaxAttempts belum tercapai.
+// Mengembalikan false jika percobaan sudah habis atau OTP sudah diganti/dibatalkan oleh request lain.
+// Panggil sebelum CheckOTP agar tebakan paralel tidak bisa melewati batas.
+func ConsumeOTPAttempt(userID uint, tokenColumn, tokenHash, attemptsColumn string) (bool, error) {
+	result := database.DB.Model(&models.User{}).
+		Where("id = ? AND "+tokenColumn+" = ? AND "+attemptsColumn+" < ?", userID, tokenHash, constants.OTPMaxAttempts).
+		UpdateColumn(attemptsColumn, gorm.Expr(attemptsColumn+" + 1"))
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Token ekspor disimpan di cache token laporan dengan prefix agar tidak bisa
+// dipakai untuk mengunduh laporan admin (dan sebaliknya).
+const PersonalExportTokenPrefix = "export:"
+
+const personalExportFilePrefix = "srikandi-export-"
+
+// PersonalExportPath mengembalikan lokasi arsip ekspor milik user untuk token tertentu.
+// UUID user ikut disimpan di nama file agar arsip bisa ditemukan saat akun dihapus.
+func PersonalExportPath(userUUID, token string) string {
+	return filepath.Join(os.TempDir(), fmt.Sprintf("%s%s-%s.zip", personalExportFilePrefix, userUUID, token))
+}
+
+// FindPersonalExport mencari arsip ekspor (beserta file .part-nya) berdasarkan token.
+// Token harus sudah divalidasi sebagai UUID oleh pemanggil.
+func FindPersonalExport(token string) (string, bool) {
+	matches, err := filepath.Glob(filepath.Join(os.TempDir(), personalExportFilePrefix+"*-"+token+".zip"))
+	if err == nil && len(matches) > 0 {
+		return matches[0], true
+	}
+	matches, err = filepath.Glob(filepath.Join(os.TempDir(), personalExportFilePrefix+"*-"+token+".zip.part"))
+	if err == nil && len(matches) > 0 {
+		return strings.TrimSuffix(matches[0], ".part"), true
+	}
+	return "", false
+}
+
+// PurgePersonalExports menghapus semua arsip ekspor milik user dari disk dan membatalkan token unduhannya.
+func PurgePersonalExports(userUUID string) error {
+	prefix := personalExportFilePrefix + userUUID + "-"
+	matches, err := filepath.Glob(filepath.Join(os.TempDir(), prefix+"*"))
+	if err != nil {
+		return err
+	}
+
+	for _, path := range matches {
+		name := filepath.Base(path)
+		token := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".part"), ".zip")
+		UseReportToken(PersonalExportTokenPrefix + token)
+
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"ipincamp/srikandi-sehat/database"
+	"ipincamp/srikandi-sehat/src/constants"
+	"ipincamp/srikandi-sehat/src/models/region"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+// RegionLevelOf menentukan tingkat wilayah dari panjang kode (2 provinsi, 4 kabupaten/kota,
+// 7 kecamatan, 10 desa). Mengembalikan string kosong jika panjang kode tidak dikenal.
+func RegionLevelOf(code string) string {
+	switch len(code) {
+	case 2:
+		return "province"
+	case 4:
+		return "regency"
+	case 7:
+		return "district"
+	case 10:
+		return "village"
+	default:
+		return ""
+	}
+}
+
+// RegionCodeExists memeriksa apakah kode wilayah terdaftar di tabel wilayah yang sesuai tingkatnya.
+func RegionCodeExists(code string) (bool, error) {
+	var model interface{}
+	switch RegionLevelOf(code) {
+	case "province":
+		model = &region.Province{}
+	case "regency":
+		model = &region.Regency{}
+	case "district":
+		model = &region.District{}
+	case "village":
+		model = &region.Village{}
+	default:
+		return false, nil
+	}
+
+	var count int64
+	if err := database.DB.Model(model).Where("code = ?", code).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetUserRegionScope mengembalikan kode wilayah yang boleh diakses user di rute admin.
+// restricted bernilai false untuk admin dan user tanpa role berwilayah; selain itu
+// akses dibatasi ke gabungan wilayah dari semua role berwilayah milik user.
+func GetUserRegionScope(userUUID string) (codes []string, restricted bool, err error) {
+	roleNames, err := GetUserRoles(userUUID)
+	if err != nil {
+		return nil, false, err
+	}
+	if slices.Contains(roleNames, string(constants.AdminRole)) {
+		return nil, false, nil
+	}
+
+	cacheMutex.RLock()
+	defer cacheMutex.RUnlock()
+	for _, name := range roleNames {
+		if role, found := roleCache[name]; found && role.RegionCode.Valid {
+			codes = append(codes, role.RegionCode.String)
+		}
+	}
+	return codes, len(codes) > 0, nil
+}
+
+// UserIDsInRegions membangun subquery id user yang desa pada profilnya berada di salah satu wilayah.
+// Kode wilayah bersifat hierarkis, sehingga cukup dicocokkan sebagai prefix kode desa.
+func UserIDsInRegions(codes []string) *gorm.DB {
+	condition := database.DB
+	for i, code := range codes {
+		if i == 0 {
+			condition = condition.Where("villages.code LIKE ?", code+"%")
+		} else {
+			condition = condition.Or("villages.code LIKE ?", code+"%")
+		}
+	}
+
+	return database.DB.Table("profiles").
+		Select("profiles.user_id").
+		Joins("JOIN villages ON profiles.village_id = villages.id").
+		Where(condition)
+}
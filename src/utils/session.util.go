@@ -0,0 +1,232 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"ipincamp/srikandi-sehat/config"
+	"ipincamp/srikandi-sehat/database"
+	"ipincamp/srikandi-sehat/src/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid")
+	ErrRefreshTokenExpired = errors.New("refresh token has expired")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+)
+
+// Alasan pencabutan sesi yang disimpan di kolom revoked_reason.
+const (
+	SessionRevokedLogout         = "logout"
+	SessionRevokedReuse          = "reuse_detected"
+	SessionRevokedPasswordChange = "password_changed"
+	SessionRevokedByUser         = "revoked_by_user"
+	SessionRevokedPrivilegeGrant = "privilege_granted"
+)
+
+// getRefreshTokenTTL membaca masa berlaku refresh token dari REFRESH_TOKEN_EXPIRATION_DAYS (default 30 hari).
+func getRefreshTokenTTL() time.Duration {
+	days, err := strconv.Atoi(config.Get("REFRESH_TOKEN_EXPIRATION_DAYS"))
+	if err != nil || days <= 0 {
+		days = 30
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// hashRefreshToken mengembalikan SHA-256 (hex) dari refresh token.
+// Refresh token memiliki entropi tinggi sehingga hash cepat sudah cukup.
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// newRefreshToken membuat refresh token berformat "<session_uuid>.<secret>".
+func newRefreshToken(sessionUUID string) (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return sessionUUID + "." + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// CreateSession membuat sesi baru untuk user dan mengembalikan access token serta refresh token.
+// deviceName bersifat opsional dan hanya dipakai untuk ditampilkan di daftar sesi aktif.
+func CreateSession(user models.User, deviceName, userAgent, ipAddress string) (string, string, error) {
+	now := time.Now()
+	session := models.Session{
+		// UUID diisi lebih awal karena dibutuhkan untuk menyusun refresh token.
+		UUID:       uuid.New().String(),
+		UserID:     user.ID,
+		DeviceName: truncate(deviceName, 100),
+		UserAgent:  truncate(userAgent, 255),
+		IPAddress:  ipAddress,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(getRefreshTokenTTL()),
+	}
+	refreshToken, err := newRefreshToken(session.UUID)
+	if err != nil {
+		return "", "", err
+	}
+	session.RefreshTokenHash = hashRefreshToken(refreshToken)
+
+	accessToken, jti, accessExpiresAt, err := GenerateJWT(user, session.UUID)
+	if err != nil {
+		return "", "", err
+	}
+	session.AccessTokenJTI = jti
+	session.AccessTokenExpiresAt = sql.NullTime{Time: accessExpiresAt, Valid: true}
+
+	if err := database.DB.Create(&session).Error; err != nil {
+		return "", "", err
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+// RotateRefreshToken menukar refresh token lama dengan pasangan token baru.
+// Access token sebelumnya ikut dicabut, sehingga setiap sesi hanya memiliki satu access token aktif.
+// Jika refresh token lama yang sudah dirotasi dipakai lagi, seluruh sesi dicabut.
+func RotateRefreshToken(refreshToken, userAgent, ipAddress string) (models.User, string, string, error) {
+	var user models.User
+
+	sessionUUID, _, found := strings.Cut(refreshToken, ".")
+	if !found || sessionUUID == "" {
+		return user, "", "", ErrRefreshTokenInvalid
+	}
+
+	var accessToken, newRefreshTokenValue string
+	var previous models.Session
+	var reused bool
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&previous, "uuid = ?", sessionUUID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRefreshTokenInvalid
+			}
+			return err
+		}
+
+		if previous.RevokedAt.Valid {
+			return ErrRefreshTokenInvalid
+		}
+
+		if previous.RefreshTokenHash != hashRefreshToken(refreshToken) {
+			// Token lama dari family ini dipakai ulang: anggap bocor dan matikan sesinya.
+			reused = true
+			return tx.Model(&previous).Updates(map[string]interface{}{
+				"revoked_at":     time.Now(),
+				"revoked_reason": SessionRevokedReuse,
+			}).Error
+		}
+
+		if time.Now().After(previous.ExpiresAt) {
+			return ErrRefreshTokenExpired
+		}
+
+		if err := tx.Preload("Roles").Preload("Profile").First(&user, previous.UserID).Error; err != nil {
+			return err
+		}
+
+		var err error
+		newRefreshTokenValue, err = newRefreshToken(previous.UUID)
+		if err != nil {
+			return err
+		}
+
+		var jti string
+		var accessExpiresAt time.Time
+		accessToken, jti, accessExpiresAt, err = GenerateJWT(user, previous.UUID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		return tx.Model(&models.Session{}).Where("id = ?", previous.ID).Updates(map[string]interface{}{
+			"refresh_token_hash":      hashRefreshToken(newRefreshTokenValue),
+			"access_token_jti":        jti,
+			"access_token_expires_at": accessExpiresAt,
+			"user_agent":              truncate(userAgent, 255),
+			"ip_address":              ipAddress,
+			"last_used_at":            now,
+			"expires_at":              now.Add(getRefreshTokenTTL()),
+		}).Error
+	})
+	if err != nil {
+		return user, "", "", err
+	}
+
+	if previous.AccessTokenExpiresAt.Valid {
+		if err := RevokeToken(previous.AccessTokenJTI, previous.AccessTokenExpiresAt.Time); err != nil {
+			ErrorLogger.Printf("Failed to revoke access token of session %s: %v", sessionUUID, err)
+		}
+	}
+
+	if reused {
+		AuthLogger.Printf("Refresh token reuse detected, session revoked: %s", sessionUUID)
+		return user, "", "", ErrRefreshTokenReused
+	}
+
+	return user, accessToken, newRefreshTokenValue, nil
+}
+
+// RevokeSession mencabut sesi berdasarkan UUID beserta access token terakhirnya.
+// Sesi yang sudah dicabut tidak diubah.
+func RevokeSession(sessionUUID, reason string) error {
+	var session models.Session
+	if err := database.DB.First(&session, "uuid = ?", sessionUUID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if session.RevokedAt.Valid {
+		return nil
+	}
+
+	if session.AccessTokenExpiresAt.Valid {
+		if err := RevokeToken(session.AccessTokenJTI, session.AccessTokenExpiresAt.Time); err != nil {
+			return err
+		}
+	}
+
+	return database.DB.Model(&session).Updates(map[string]interface{}{
+		"revoked_at":     time.Now(),
+		"revoked_reason": reason,
+	}).Error
+}
+
+// RevokeOtherUserSessions mencabut semua sesi aktif milik user kecuali sesi keepSessionUUID.
+// Mengembalikan jumlah sesi yang dicabut.
+func RevokeOtherUserSessions(userID uint, keepSessionUUID, reason string) (int, error) {
+	var sessionUUIDs []string
+	if err := database.DB.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL AND uuid <> ?", userID, keepSessionUUID).
+		Pluck("uuid", &sessionUUIDs).Error; err != nil {
+		return 0, err
+	}
+
+	for _, sessionUUID := range sessionUUIDs {
+		if err := RevokeSession(sessionUUID, reason); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(sessionUUIDs), nil
+}
+
+func truncate(s string, limit int) string {
+	if len(s) > limit {
+		return s[:limit]
+	}
+	return s
+}
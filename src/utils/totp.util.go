@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"ipincamp/srikandi-sehat/config"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Parameter TOTP mengikuti default RFC 6238 yang didukung semua aplikasi authenticator.
+const (
+	totpIssuer    = "Srikandi Sehat"
+	totpDigits    = 6
+	totpPeriod    = 30 // detik
+	totpSkewSteps = 1  // toleransi selisih jam perangkat (±30 detik)
+)
+
+var base32NoPadding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret membuat secret acak 160-bit dalam format base32.
+func GenerateTOTPSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return base32NoPadding.EncodeToString(secret), nil
+}
+
+// TOTPProvisioningURI membuat URI otpauth:// yang bisa dijadikan QR code oleh aplikasi.
+func TOTPProvisioningURI(secret, accountName string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", totpIssuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprintf("%d", totpDigits))
+	query.Set("period", fmt.Sprintf("%d", totpPeriod))
+
+	label := url.PathEscape(totpIssuer + ":" + accountName)
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, query.Encode())
+}
+
+// generateTOTPCode menghitung kode HOTP (RFC 4226) untuk time step tertentu.
+func generateTOTPCode(key []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, value%mod)
+}
+
+// ValidateTOTP memeriksa kode TOTP terhadap secret. Time step yang sudah pernah dipakai
+// (<= lastUsedStep) ditolak untuk mencegah replay. Mengembalikan step yang cocok.
+func ValidateTOTP(secret, code string, lastUsedStep int64) (int64, bool) {
+	key, err := base32NoPadding.DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+
+	currentStep := time.Now().Unix() / totpPeriod
+	for offset := int64(-totpSkewSteps); offset <= totpSkewSteps; offset++ {
+		step := currentStep + offset
+		if step <= lastUsedStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(generateTOTPCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// GenerateRecoveryCodes membuat n kode pemulihan sekali pakai berformat "xxxxx-xxxxx".
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		raw := make([]byte, 7)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, err
+		}
+		encoded := strings.ToLower(base32NoPadding.EncodeToString(raw))[:10]
+		codes = append(codes, encoded[:5]+"-"+encoded[5:])
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode menormalkan dan meng-hash kode pemulihan (SHA-256 hex).
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// twoFactorCipher membuat AES-GCM dari TWO_FACTOR_ENCRYPTION_KEY.
+func twoFactorCipher() (cipher.AEAD, error) {
+	secretKey := config.Get("TWO_FACTOR_ENCRYPTION_KEY")
+	if secretKey == "" {
+		return nil, errors.New("TWO_FACTOR_ENCRYPTION_KEY is not set")
+	}
+
+	key := sha256.Sum256([]byte(secretKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// EncryptTwoFactorSecret mengenkripsi secret TOTP sebelum disimpan ke database.
+func EncryptTwoFactorSecret(secret string) (string, error) {
+	gcm, err := twoFactorCipher()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptTwoFactorSecret membuka secret TOTP yang tersimpan di database.
+func DecryptTwoFactorSecret(encrypted string) (string, error) {
+	gcm, err := twoFactorCipher()
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("encrypted two-factor secret is too short")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	secret, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
@@ -28,6 +28,21 @@ func SetupValidator() {
 		t, _ := ut.T("password_strength", fe.Field())
 		return t
 	})
+
+	validate.RegisterValidation("access_name", ValidateAccessName)
+	validate.RegisterTranslation("access_name", trans, func(ut ut.Translator) error {
+		return ut.Add("access_name", "The {0} field may only contain lowercase letters, numbers, and single '.', '_' or '-' separators (e.g. reports.export).", true)
+	}, func(ut ut.Translator, fe validator.FieldError) string {
+		t, _ := ut.T("access_name", fe.Field())
+		return t
+	})
+}
+
+var accessNamePattern = regexp.MustCompile(`^[a-z0-9]+([._-][a-z0-9]+)*$`)
+
+// ValidateAccessName memvalidasi nama role/permission, contoh: "admin", "health-worker", "reports.export".
+func ValidateAccessName(fl validator.FieldLevel) bool {
+	return accessNamePattern.MatchString(fl.Field().String())
 }
 
 func ValidatePasswordStrength(fl validator.FieldLevel) bool {
@@ -0,0 +1,89 @@
+package workers
+
+import (
+	"ipincamp/srikandi-sehat/database"
+	"ipincamp/srikandi-sehat/src/models"
+	"ipincamp/srikandi-sehat/src/models/menstrual"
+	"ipincamp/srikandi-sehat/src/utils"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PurgeScheduledAccountDeletions permanently deletes accounts whose deletion grace period has ended.
+func PurgeScheduledAccountDeletions() {
+	utils.InfoLogger.Println("Running Job: PurgeScheduledAccountDeletions...")
+	var users []models.User
+
+	err := database.DB.Unscoped().
+		Select("id", "uuid").
+		Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", time.Now()).
+		Find(&users).Error
+	if err != nil {
+		utils.ErrorLogger.Printf("Error fetching accounts scheduled for deletion: %v\n", err)
+		return
+	}
+
+	for _, user := range users {
+		// Cabut token lebih dulu agar access token yang masih hidup tidak bisa dipakai lagi.
+		if err := utils.RevokeAllUserTokens(user.ID, "account_deleted"); err != nil {
+			utils.ErrorLogger.Printf("Failed to revoke tokens of user %d before deletion: %v\n", user.ID, err)
+			continue
+		}
+
+		// Arsip ekspor data pribadi yang belum diunduh ikut dihapus beserta tautannya.
+		if err := utils.PurgePersonalExports(user.UUID); err != nil {
+			utils.ErrorLogger.Printf("Failed to delete data exports of user %d: %v\n", user.ID, err)
+			continue
+		}
+
+		if err := purgeUserData(user.ID); err != nil {
+			utils.ErrorLogger.Printf("Failed to purge data of user %d: %v\n", user.ID, err)
+			continue
+		}
+
+		utils.InvalidateUserRolesCache(user.UUID)
+		utils.AuthLogger.Printf("Account permanently deleted after grace period: %s", user.UUID)
+	}
+	utils.InfoLogger.Println("Job: PurgeScheduledAccountDeletions finished.")
+}
+
+// purgeUserData hard-deletes the user and every row that belongs to them in a single transaction.
+func purgeUserData(userID uint) error {
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		symptomLogIDs := tx.Model(&menstrual.SymptomLog{}).Select("id").Where("user_id = ?", userID)
+		if err := tx.Where("symptom_log_id IN (?)", symptomLogIDs).Delete(&menstrual.SymptomLogDetail{}).Error; err != nil {
+			return err
+		}
+
+		userOwned := []interface{}{
+			&menstrual.SymptomLog{},
+			&menstrual.FlowLog{},
+			&menstrual.MenstrualCycle{},
+			&models.Notification{},
+			&models.Profile{},
+			&models.Session{},
+			&models.TwoFactorRecoveryCode{},
+			&models.MaintenanceWhitelist{},
+			&models.Consent{},
+		}
+		for _, model := range userOwned {
+			if err := tx.Unscoped().Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		userUUIDs := tx.Model(&models.User{}).Select("uuid").Where("id = ?", userID)
+		if err := tx.Where("scope = ? AND identifier IN (?)", models.LoginLockoutScopeAccount, userUUIDs).Delete(&models.LoginLockout{}).Error; err != nil {
+			return err
+		}
+
+		for _, joinTable := range []string{"user_roles", "user_permissions"} {
+			if err := tx.Exec("DELETE FROM "+joinTable+" WHERE user_id = ?", userID).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Unscoped().Delete(&models.User{}, userID).Error
+	})
+}
@@ -39,9 +39,23 @@ func CheckLateMenstrualCycles() {
 			durationSinceEnd := time.Since(latestCycle.EndDate.Time)
 			daysSinceEnd := int(durationSinceEnd.Hours() / 24)
 
-			if daysSinceEnd > constants.CycleLateThresholdDays {
+			// Jika riwayat cukup, keterlambatan diukur dari batas akhir rentang prediksi user;
+			// jika tidak, gunakan ambang tetap sejak siklus terakhir selesai.
+			isLate := daysSinceEnd > constants.CycleLateThresholdDays
+			body := fmt.Sprintf("Sudah %d hari sejak siklus terakhir Anda selesai dan siklus baru belum dimulai. Segera periksakan diri jika Anda khawatir.", daysSinceEnd)
+
+			prediction, ok, err := utils.PredictNextPeriodForUser(database.DB, user.ID)
+			if err != nil {
+				utils.ErrorLogger.Printf("Error predicting next period for user %d: %v\n", user.ID, err)
+			} else if ok {
+				lateSince := prediction.LatestStart.AddDate(0, 0, constants.CycleLatePredictionGraceDays)
+				isLate = time.Now().After(lateSince)
+				daysLate := int(time.Since(prediction.PredictedStart).Hours() / 24)
+				body = fmt.Sprintf("Menstruasi Anda sudah terlambat %d hari dari perkiraan (%s). Segera periksakan diri jika Anda khawatir.", daysLate, prediction.PredictedStart.Format("2 January 2006"))
+			}
+
+			if isLate {
 				title := "Peringatan Keterlambatan Siklus"
-				body := fmt.Sprintf("Sudah %d hari sejak siklus terakhir Anda selesai dan siklus baru belum dimulai. Segera periksakan diri jika Anda khawatir.", daysSinceEnd)
 
 				err := utils.SendFCMNotification(user.ID, user.FcmToken, title, body, nil)
 				if err != nil {